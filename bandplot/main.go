@@ -0,0 +1,76 @@
+// Command bandplot plots the holon, quasiparticle and (optionally) pair
+// dispersions of a solved Environment along a path through high-symmetry
+// points of the Brillouin zone.
+//
+// Usage:
+//
+//	bandplot [-path G,X,M,G] [-n 50] [-pairs] [-out prefix] [-grapher path] env.json
+//
+// env.json holds the Environment, e.g. a solution written by one of the
+// temperature-regime solvers. The holon bands are written to
+// prefix_holon.{png,eps} and the pair bands to prefix_pair.{png,eps}.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+import (
+	"github.com/tflovorn/scExplorer/bands"
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+)
+
+func main() {
+	pathFlag := flag.String("path", "G,X,M,G", "comma-separated high-symmetry points (G, X, Y, M, Z)")
+	n := flag.Int("n", 50, "points per path segment")
+	pairs := flag.Bool("pairs", false, "also plot the pair dispersions")
+	out := flag.String("out", "bands", "output file prefix")
+	grapher := flag.String("grapher", "plots/grapher.py", "path to grapher.py")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: bandplot [flags] env.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), strings.Split(*pathFlag, ","), *n, *pairs, *out, *grapher); err != nil {
+		fmt.Fprintf(os.Stderr, "bandplot: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(envPath string, names []string, n int, pairs bool, out, grapher string) error {
+	data, err := ioutil.ReadFile(envPath)
+	if err != nil {
+		return err
+	}
+	env, err := tempAll.NewEnvironment(string(data))
+	if err != nil {
+		return err
+	}
+	path, err := bzone.SymmetryPath(names, n)
+	if err != nil {
+		return err
+	}
+	holon := bands.HolonBands(env, path.Ks)
+	if err := bands.PlotBands(path, holon, "$E$", out+"_holon", grapher); err != nil {
+		return err
+	}
+	if !pairs {
+		return nil
+	}
+	pairBands, failed, err := bands.PairBands(env, path.Ks)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "bandplot: pair energy not found at %d points; omitted from plot\n", failed)
+	}
+	return bands.PlotBands(path, pairBands, "$\\omega$", out+"_pair", grapher)
+}
@@ -0,0 +1,113 @@
+// Holon, quasiparticle and pair dispersions along paths through k-space.
+package bands
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempLow"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Energies of one band at each point of a path. Energies[i] is NaN if the
+// band could not be found at the i'th point.
+type Band struct {
+	Label    string
+	Energies []float64
+}
+
+// The holon dispersion Xi_h and the Bogolyubov quasiparticle bands
+// +/- BogoEnergy at each k in ks, all measured from the holon chemical
+// potential so that Xi_h is the F0 = 0 limit of the upper and lower bands.
+func HolonBands(env *tempAll.Environment, ks []vec.Vector) []Band {
+	xi := Band{"$\\xi_h$", make([]float64, len(ks))}
+	upper := Band{"$E_k$", make([]float64, len(ks))}
+	lower := Band{"$-E_k$", make([]float64, len(ks))}
+	for i, k := range ks {
+		xi.Energies[i] = env.Xi_h(k)
+		E := env.BogoEnergy(k)
+		upper.Energies[i], lower.Energies[i] = E, -E
+	}
+	return []Band{xi, upper, lower}
+}
+
+// The pair dispersions at each k in ks, which must have three components.
+// Above T_c (env.F0 = 0) these are tempCrit.OmegaPlus and OmegaMinus; below
+// T_c they are tempLow.OmegaPair for each of the four (r, s) branches.
+// Points at which the pair energy root was not found are NaN; the number of
+// such points is returned.
+func PairBands(env *tempAll.Environment, ks []vec.Vector) ([]Band, int, error) {
+	for _, k := range ks {
+		if len(k) != 3 {
+			return nil, 0, fmt.Errorf("pair dispersion requires three-component k; got %v", k)
+		}
+	}
+	type omegaFn func(k vec.Vector) (float64, error)
+	var labels []string
+	var fns []omegaFn
+	if env.F0 == 0.0 {
+		labels = []string{"$\\omega_+$", "$\\omega_-$"}
+		fns = []omegaFn{
+			func(k vec.Vector) (float64, error) { return tempCrit.OmegaPlus(env, k) },
+			func(k vec.Vector) (float64, error) { return tempCrit.OmegaMinus(env, k) },
+		}
+	} else {
+		for _, r := range []int{1, -1} {
+			for _, s := range []int{1, -1} {
+				r, s := r, s
+				labels = append(labels, fmt.Sprintf("$\\omega_{%+d,%+d}$", r, s))
+				fns = append(fns, func(k vec.Vector) (float64, error) {
+					return tempLow.OmegaPair(env, k, r, s)
+				})
+			}
+		}
+	}
+	bands := make([]Band, len(fns))
+	failed := 0
+	for b, fn := range fns {
+		bands[b] = Band{labels[b], make([]float64, len(ks))}
+		for i, k := range ks {
+			omega, err := fn(k)
+			if err != nil {
+				omega = math.NaN()
+				failed++
+			}
+			bands[b].Energies[i] = omega
+		}
+	}
+	return bands, failed, nil
+}
+
+// Plot bands along path, with the high-symmetry points of the path marked.
+// Points where a band is NaN are omitted.
+func PlotBands(path *bzone.Path, bands []Band, ylabel, outPrefix, grapherPath string) error {
+	styles := []string{"k-", "r-", "b-", "g-", "m-", "c-"}
+	data := make([]plots.Series, len(bands))
+	seriesParams := make([]map[string]string, len(bands))
+	for b, band := range bands {
+		if len(band.Energies) != len(path.Ks) {
+			return fmt.Errorf("band %s has %d energies; expected %d", band.Label, len(band.Energies), len(path.Ks))
+		}
+		xs, ys := []float64{}, []float64{}
+		for i, E := range band.Energies {
+			if !math.IsNaN(E) {
+				xs = append(xs, path.Dist[i])
+				ys = append(ys, E)
+			}
+		}
+		data[b] = plots.MakeSeries(xs, ys)
+		seriesParams[b] = map[string]string{"label": band.Label, "style": styles[b%len(styles)]}
+	}
+	params := map[string]string{
+		plots.FILE_KEY:   outPrefix,
+		plots.XLABEL_KEY: "",
+		plots.YLABEL_KEY: ylabel,
+	}
+	plots.SetXTicks(params, path.TickDist, path.TickLabels)
+	return plots.PlotMPL(data, params, seriesParams, grapherPath)
+}
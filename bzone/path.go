@@ -0,0 +1,93 @@
+package bzone
+
+import (
+	"fmt"
+	"math"
+)
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// High-symmetry points of the simple tetragonal Brillouin zone, as
+// three-component vectors (kz = 0 except at Z).
+var symmetryPoints = map[string]vec.Vector{
+	"G": {0.0, 0.0, 0.0},
+	"X": {math.Pi, 0.0, 0.0},
+	"Y": {0.0, math.Pi, 0.0},
+	"M": {math.Pi, math.Pi, 0.0},
+	"Z": {0.0, 0.0, math.Pi},
+}
+
+// Plot labels for the high-symmetry points.
+var symmetryLabels = map[string]string{
+	"G": "$\\Gamma$",
+	"X": "$X$",
+	"Y": "$Y$",
+	"M": "$M$",
+	"Z": "$Z$",
+}
+
+// A piecewise-linear path through k-space. Dist[i] is the distance along the
+// path to Ks[i]; the path passes through the high-symmetry point labeled
+// TickLabels[j] at distance TickDist[j].
+type Path struct {
+	Ks         []vec.Vector
+	Dist       []float64
+	TickDist   []float64
+	TickLabels []string
+}
+
+// Create a path through the named high-symmetry points ("G" for Gamma, "X",
+// "Y", "M" and "Z"), with pointsPerSegment points on each straight segment
+// (not counting the segment's final point, which begins the next segment).
+// For example, names = {"G", "X", "M", "G", "Z"} gives Gamma-X-M-Gamma-Z.
+// All points of the path have three components.
+func SymmetryPath(names []string, pointsPerSegment int) (*Path, error) {
+	if len(names) < 2 {
+		return nil, fmt.Errorf("path needs at least 2 points; got %d", len(names))
+	}
+	if pointsPerSegment < 1 {
+		return nil, fmt.Errorf("path needs at least 1 point per segment; got %d", pointsPerSegment)
+	}
+	corners := make([]vec.Vector, len(names))
+	for i, name := range names {
+		k, ok := symmetryPoints[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown high-symmetry point %q", name)
+		}
+		corners[i] = k
+	}
+	p := &Path{}
+	for i := 0; i < len(corners)-1; i++ {
+		start, end := corners[i], corners[i+1]
+		for j := 0; j < pointsPerSegment; j++ {
+			t := float64(j) / float64(pointsPerSegment)
+			k := vec.ZeroVector(3)
+			for d := 0; d < 3; d++ {
+				k[d] = start[d] + t*(end[d]-start[d])
+			}
+			p.Ks = append(p.Ks, k)
+		}
+	}
+	last := vec.ZeroVector(3)
+	copy(last, corners[len(corners)-1])
+	p.Ks = append(p.Ks, last)
+	p.Dist = PathDistance(p.Ks)
+	for i, name := range names {
+		p.TickDist = append(p.TickDist, p.Dist[i*pointsPerSegment])
+		p.TickLabels = append(p.TickLabels, symmetryLabels[name])
+	}
+	return p, nil
+}
+
+// Cumulative distance along the sequence of points ks.
+func PathDistance(ks []vec.Vector) []float64 {
+	dist := make([]float64, len(ks))
+	for i := 1; i < len(ks); i++ {
+		step := 0.0
+		for d := range ks[i] {
+			diff := ks[i][d] - ks[i-1][d]
+			step += diff * diff
+		}
+		dist[i] = dist[i-1] + math.Sqrt(step)
+	}
+	return dist
+}
@@ -0,0 +1,27 @@
+package bzone
+
+import (
+	"math"
+	"testing"
+)
+
+// The Gamma-X-M-Gamma path should pass through its corners at distances
+// 0, pi, 2 pi and (2 + sqrt(2)) pi.
+func TestSymmetryPath(t *testing.T) {
+	p, err := SymmetryPath([]string{"G", "X", "M", "G"}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Ks) != 31 {
+		t.Fatalf("path has %d points; expected 31", len(p.Ks))
+	}
+	expected := []float64{0.0, math.Pi, 2.0 * math.Pi, (2.0 + math.Sqrt(2.0)) * math.Pi}
+	for i, d := range expected {
+		if math.Abs(p.TickDist[i]-d) > 1e-12 {
+			t.Fatalf("tick %d at distance %v; expected %v", i, p.TickDist[i], d)
+		}
+	}
+	if _, err := SymmetryPath([]string{"G", "Q"}, 10); err == nil {
+		t.Fatalf("expected error for unknown symmetry point")
+	}
+}
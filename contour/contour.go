@@ -0,0 +1,190 @@
+// Constant-value contours of periodic functions on the two-dimensional
+// Brillouin zone, found by marching squares on the bzone grid.
+package contour
+
+import "math"
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// A closed contour. Points are unwrapped: consecutive points are close
+// together even where the contour crosses the zone boundary, so that a
+// contour which wraps around the zone ends one reciprocal lattice vector
+// away from where it started. Winding gives the number of times the contour
+// wraps around the zone in the x and y directions; a contour with zero
+// winding encloses a pocket. Points[0] is not repeated at the end.
+//
+// Contours are oriented so that fn < level lies to the left.
+type Polyline struct {
+	Points  []vec.Vector
+	Winding [2]int
+}
+
+// Kinds of contour, as returned by Kind.
+const (
+	ElectronPocket = iota // encloses a region with fn < level
+	HolePocket            // encloses a region with fn >= level
+	Open                  // wraps around the zone
+)
+
+// Find the contours fn(k) = level on the L by L grid covering the first
+// Brillouin zone, with k_i = -pi + 2 pi i / L as in bzone. fn is assumed to
+// be periodic.
+func Extract(fn func(k vec.Vector) float64, level float64, L int) []Polyline {
+	step := 2.0 * math.Pi / float64(L)
+	vals := make([][]float64, L)
+	k := vec.ZeroVector(2)
+	for i := 0; i < L; i++ {
+		vals[i] = make([]float64, L)
+		for j := 0; j < L; j++ {
+			k[0], k[1] = -math.Pi+float64(i)*step, -math.Pi+float64(j)*step
+			vals[i][j] = fn(k)
+		}
+	}
+	below := func(i, j int) bool {
+		return vals[mod(i, L)][mod(j, L)] < level
+	}
+	// segments maps the grid edge where a segment starts to the edge where
+	// it ends
+	segments := make(map[edge]edge)
+	for i := 0; i < L; i++ {
+		for j := 0; j < L; j++ {
+			// corners counterclockwise from (i, j)
+			corners := [4][2]int{{i, j}, {i + 1, j}, {i + 1, j + 1}, {i, j + 1}}
+			edges := [4]edge{{i, j, 0}, {mod(i+1, L), j, 1}, {i, mod(j+1, L), 0}, {i, j, 1}}
+			// crossings in counterclockwise order; up is true where the
+			// boundary goes from below level to above
+			var crossed []edge
+			var up []bool
+			for m := 0; m < 4; m++ {
+				a, b := corners[m], corners[(m+1)%4]
+				ba, bb := below(a[0], a[1]), below(b[0], b[1])
+				if ba != bb {
+					crossed = append(crossed, edges[m])
+					up = append(up, ba)
+				}
+			}
+			if len(crossed) == 0 {
+				continue
+			}
+			// Each segment runs from an upward crossing to a downward
+			// crossing, leaving the region below level on its left. In a
+			// saddle cell, the value at the center decides whether the
+			// regions below level are connected.
+			shift := 1
+			if len(crossed) == 4 {
+				center := (vals[i][j] + vals[mod(i+1, L)][j] + vals[mod(i+1, L)][mod(j+1, L)] + vals[i][mod(j+1, L)]) / 4.0
+				if center >= level {
+					shift = 3
+				}
+			}
+			for m := range crossed {
+				if up[m] {
+					segments[crossed[m]] = crossed[(m+shift)%len(crossed)]
+				}
+			}
+		}
+	}
+	// join segments into closed contours
+	point := func(e edge) vec.Vector {
+		i2, j2 := e.i, e.j
+		if e.dir == 0 {
+			i2++
+		} else {
+			j2++
+		}
+		va, vb := vals[e.i][e.j], vals[mod(i2, L)][mod(j2, L)]
+		t := (level - va) / (vb - va)
+		p := vec.Vector{-math.Pi + float64(e.i)*step, -math.Pi + float64(e.j)*step}
+		p[e.dir] += t * step
+		return p
+	}
+	lines := []Polyline{}
+	for len(segments) > 0 {
+		var start edge
+		for e := range segments {
+			start = e
+			break
+		}
+		first := point(start)
+		line := Polyline{Points: []vec.Vector{first}}
+		prev := first
+		current := start
+		for {
+			next, ok := segments[current]
+			if !ok {
+				// shouldn't get here: contours on the periodic grid close
+				break
+			}
+			delete(segments, current)
+			if next == start {
+				break
+			}
+			raw := point(next)
+			p := vec.Vector{prev[0] + wrap(raw[0]-prev[0]), prev[1] + wrap(raw[1]-prev[1])}
+			line.Points = append(line.Points, p)
+			prev, current = p, next
+		}
+		// distance from the last point back to the first
+		for d := 0; d < 2; d++ {
+			closing := prev[d] + wrap(first[d]-prev[d])
+			line.Winding[d] = int(math.Floor((closing-first[d])/(2.0*math.Pi) + 0.5))
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Kind of contour: ElectronPocket, HolePocket or Open.
+func (p Polyline) Kind() int {
+	if p.Winding[0] != 0 || p.Winding[1] != 0 {
+		return Open
+	}
+	// the region below level is on the left, so it is enclosed if the
+	// contour runs counterclockwise
+	if p.SignedArea() > 0.0 {
+		return ElectronPocket
+	}
+	return HolePocket
+}
+
+// Area enclosed by the contour, positive if the contour runs
+// counterclockwise. Only meaningful for contours with zero winding.
+func (p Polyline) SignedArea() float64 {
+	area := 0.0
+	N := len(p.Points)
+	for n := 0; n < N; n++ {
+		a, b := p.Points[n], p.Points[(n+1)%N]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	return area / 2.0
+}
+
+// Length of the contour.
+func (p Polyline) Length() float64 {
+	length := 0.0
+	N := len(p.Points)
+	for n := 0; n < N; n++ {
+		a, b := p.Points[n], p.Points[(n+1)%N]
+		if n == N-1 {
+			// the closing segment of an open contour crosses the zone
+			b = vec.Vector{b[0] + 2.0*math.Pi*float64(p.Winding[0]), b[1] + 2.0*math.Pi*float64(p.Winding[1])}
+		}
+		length += math.Hypot(b[0]-a[0], b[1]-a[1])
+	}
+	return length
+}
+
+// An edge of the grid, from point (i, j) in the +x (dir = 0) or +y (dir = 1)
+// direction.
+type edge struct {
+	i, j, dir int
+}
+
+// i mod L in [0, L).
+func mod(i, L int) int {
+	return ((i % L) + L) % L
+}
+
+// x reduced to [-pi, pi).
+func wrap(x float64) float64 {
+	return x - 2.0*math.Pi*math.Floor((x+math.Pi)/(2.0*math.Pi))
+}
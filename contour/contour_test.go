@@ -0,0 +1,53 @@
+package contour
+
+import (
+	"math"
+	"testing"
+)
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// The contour 2 - cos(kx) - cos(ky) = 0.5 is a single electron pocket around
+// k = 0, with area 3.3616 (found by direct counting on a fine grid).
+func TestExtractPocket(t *testing.T) {
+	fn := func(k vec.Vector) float64 {
+		return 2.0 - math.Cos(k[0]) - math.Cos(k[1])
+	}
+	lines := Extract(fn, 0.5, 64)
+	if len(lines) != 1 {
+		t.Fatalf("found %d contours; expected 1", len(lines))
+	}
+	if lines[0].Kind() != ElectronPocket {
+		t.Fatalf("contour has kind %d; expected ElectronPocket", lines[0].Kind())
+	}
+	if area := lines[0].SignedArea(); math.Abs(area-3.3616) > 0.01 {
+		t.Fatalf("pocket area %v; expected 3.3616", area)
+	}
+	// the same level set bounds a hole pocket of -fn
+	neg := func(k vec.Vector) float64 {
+		return -fn(k)
+	}
+	lines = Extract(neg, -0.5, 64)
+	if len(lines) != 1 || lines[0].Kind() != HolePocket {
+		t.Fatalf("expected one hole pocket for -fn")
+	}
+}
+
+// The contour cos(kx) = 0 is two lines wrapping around the zone in the y
+// direction.
+func TestExtractOpen(t *testing.T) {
+	fn := func(k vec.Vector) float64 {
+		return math.Cos(k[0])
+	}
+	lines := Extract(fn, 0.0, 32)
+	if len(lines) != 2 {
+		t.Fatalf("found %d contours; expected 2", len(lines))
+	}
+	for _, line := range lines {
+		if line.Kind() != Open || line.Winding[0] != 0 || (line.Winding[1] != 1 && line.Winding[1] != -1) {
+			t.Fatalf("contour with winding %v is not open in the y direction", line.Winding)
+		}
+		if math.Abs(line.Length()-2.0*math.Pi) > 1e-9 {
+			t.Fatalf("open contour has length %v; expected 2 pi", line.Length())
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Density of states of holons and Bogolyubov quasiparticles.
+//
+// Without broadening, the density of states is found by linear interpolation
+// of the energy on the bzone grid: each grid square is split into two
+// triangles (or each grid cube into six tetrahedra when the holon dispersion
+// depends on kz), inside which the energy is linear and the contribution to
+// N(omega) is known exactly. With broadening, each grid point instead
+// contributes a Lorentzian or Gaussian centered at its energy.
+//
+// All densities of states are normalized per lattice site and per spin so
+// that their integral over omega is 1.
+package dos
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Broadening applied to each grid point's delta function. Kind is
+// "lorentzian" or "gaussian"; Width is the half width at half maximum for a
+// Lorentzian and the standard deviation for a Gaussian. Width = 0 selects
+// triangle/tetrahedron interpolation instead.
+type Broadening struct {
+	Kind  string
+	Width float64
+}
+
+// Line shape replacing a delta function, normalized so that its integral is
+// 1. Returns an error if b.Kind is unknown or b.Width is negative; the kernel
+// is not meaningful for b.Width = 0.
+func (b Broadening) Kernel() (func(x float64) float64, error) {
+	if b.Width < 0.0 {
+		return nil, fmt.Errorf("broadening width %v must be non-negative", b.Width)
+	}
+	switch b.Kind {
+	case "", "lorentzian":
+		return func(x float64) float64 {
+			return b.Width / (math.Pi * (x*x + b.Width*b.Width))
+		}, nil
+	case "gaussian":
+		return func(x float64) float64 {
+			return math.Exp(-x*x/(2.0*b.Width*b.Width)) / (b.Width * math.Sqrt(2.0*math.Pi))
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown broadening kind %q", b.Kind)
+}
+
+// A function of k and its weight in the density of states.
+type bandFunc func(k vec.Vector) (energy, weight float64)
+
+// Holon density of states N_h(omega) at each of omegas, from the dispersion
+// Epsilon_h.
+func Holon(env *tempAll.Environment, omegas []float64, b Broadening) ([]float64, error) {
+	band := func(k vec.Vector) (float64, float64) {
+		return env.Epsilon_h(k), 1.0
+	}
+	return densityOfStates(env.PointsPerSide, env.HolonDim(), []bandFunc{band}, omegas, b)
+}
+
+// Bogolyubov quasiparticle density of states
+// N_qp(omega) = <u_k^2 delta(omega - E_k) + v_k^2 delta(omega + E_k)>_k,
+// with u_k^2 = (1 + xi_k/E_k)/2 and v_k^2 = (1 - xi_k/E_k)/2. Reduces to the
+// holon density of states (shifted by Mu_h) when F0 = 0.
+func Quasiparticle(env *tempAll.Environment, omegas []float64, b Broadening) ([]float64, error) {
+	upper := func(k vec.Vector) (float64, float64) {
+		E := env.BogoEnergy(k)
+		return E, env.CoherenceU2(k)
+	}
+	lower := func(k vec.Vector) (float64, float64) {
+		E := env.BogoEnergy(k)
+		return -E, 1.0 - env.CoherenceU2(k)
+	}
+	return densityOfStates(env.PointsPerSide, env.HolonDim(), []bandFunc{upper, lower}, omegas, b)
+}
+
+// Minimum quasiparticle excitation energy over the Brillouin zone,
+// min_k E_k. For a gap with nodes this vanishes up to the grid resolution.
+func GapEdge(env *tempAll.Environment) float64 {
+	return bzone.Min(env.PointsPerSide, env.HolonDim(), tempAll.WrapFunc(env, func(env *tempAll.Environment, k vec.Vector) float64 {
+		return env.BogoEnergy(k)
+	}))
+}
+
+// Positions of the local maxima of the density of states dos sampled at
+// omegas (which must be sorted in increasing order). Applied to the holon
+// density of states these are the van Hove singularities (smoothed by the
+// finite grid).
+func VanHove(omegas, dos []float64) ([]float64, error) {
+	if len(omegas) != len(dos) {
+		return nil, fmt.Errorf("VanHove: len(omegas) = %d differs from len(dos) = %d", len(omegas), len(dos))
+	}
+	if !sort.Float64sAreSorted(omegas) {
+		return nil, fmt.Errorf("VanHove: omegas must be sorted")
+	}
+	peaks := []float64{}
+	for i := 1; i < len(dos)-1; i++ {
+		if dos[i] > dos[i-1] && dos[i] >= dos[i+1] {
+			peaks = append(peaks, omegas[i])
+		}
+	}
+	return peaks, nil
+}
+
+// Evenly spaced omega values from min to max (inclusive).
+func Omegas(min, max float64, N int) []float64 {
+	omegas := make([]float64, N)
+	if N == 1 {
+		omegas[0] = min
+		return omegas
+	}
+	step := (max - min) / float64(N-1)
+	for i := 0; i < N; i++ {
+		omegas[i] = min + float64(i)*step
+	}
+	return omegas
+}
+
+// Sum the density of states of each band in bands over a grid with L points
+// per side in dim dimensions.
+func densityOfStates(L, dim int, bands []bandFunc, omegas []float64, b Broadening) ([]float64, error) {
+	if dim != 2 && dim != 3 {
+		return nil, fmt.Errorf("density of states: unsupported dimension %d", dim)
+	}
+	kernel, err := b.Kernel()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]float64, len(omegas))
+	for _, band := range bands {
+		energies, weights := sampleBand(L, dim, band)
+		var bandDos []float64
+		if b.Width == 0.0 {
+			bandDos = interpolatedDos(L, dim, energies, weights, omegas)
+		} else {
+			bandDos = broadenedDos(energies, weights, omegas, kernel)
+		}
+		for i := range result {
+			result[i] += bandDos[i]
+		}
+	}
+	return result, nil
+}
+
+// Evaluate band at each grid point. The returned slices are indexed by
+// i0 + L*i1 (+ L^2*i2), with k_j = -pi + 2*pi*i_j/L as in bzone.
+func sampleBand(L, dim int, band bandFunc) ([]float64, []float64) {
+	N := 1
+	for d := 0; d < dim; d++ {
+		N *= L
+	}
+	energies := make([]float64, N)
+	weights := make([]float64, N)
+	k := vec.ZeroVector(dim)
+	step := 2.0 * math.Pi / float64(L)
+	for n := 0; n < N; n++ {
+		m := n
+		for d := 0; d < dim; d++ {
+			k[d] = -math.Pi + float64(m%L)*step
+			m /= L
+		}
+		energies[n], weights[n] = band(k)
+	}
+	return energies, weights
+}
+
+// Density of states from summing kernel(omega - E_k) over the grid.
+func broadenedDos(energies, weights, omegas []float64, kernel func(x float64) float64) []float64 {
+	result := make([]float64, len(omegas))
+	N := float64(len(energies))
+	for i, omega := range omegas {
+		total := 0.0
+		for n, E := range energies {
+			total += weights[n] * kernel(omega-E)
+		}
+		result[i] = total / N
+	}
+	return result
+}
+
+// Offsets of the corners of the triangles tiling a grid square, and of the
+// tetrahedra tiling a grid cube (all sharing the cube's main diagonal).
+var triangles = [][][]int{
+	{{0, 0}, {1, 0}, {1, 1}},
+	{{0, 0}, {0, 1}, {1, 1}},
+}
+var tetrahedra = [][][]int{
+	{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {1, 1, 1}},
+	{{0, 0, 0}, {1, 0, 0}, {1, 0, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 1, 0}, {0, 1, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 0, 1}, {1, 0, 1}, {1, 1, 1}},
+	{{0, 0, 0}, {0, 0, 1}, {0, 1, 1}, {1, 1, 1}},
+}
+
+// Density of states from linear interpolation of energies within each
+// triangle (dim = 2) or tetrahedron (dim = 3) of the periodic grid. The
+// weight of each simplex is the average of the weights at its corners.
+func interpolatedDos(L, dim int, energies, weights, omegas []float64) []float64 {
+	simplices := triangles
+	if dim == 3 {
+		simplices = tetrahedra
+	}
+	numCells := len(energies)
+	// fraction of the Brillouin zone occupied by one simplex
+	volume := 1.0 / float64(numCells*len(simplices))
+	result := make([]float64, len(omegas))
+	corner := make([]float64, dim+1)
+	cornerW := make([]float64, dim+1)
+	index := make([]int, dim)
+	for n := 0; n < numCells; n++ {
+		m := n
+		for d := 0; d < dim; d++ {
+			index[d] = m % L
+			m /= L
+		}
+		for _, simplex := range simplices {
+			w := 0.0
+			for c, offset := range simplex {
+				site, stride := 0, 1
+				for d := 0; d < dim; d++ {
+					site += ((index[d] + offset[d]) % L) * stride
+					stride *= L
+				}
+				corner[c] = energies[site]
+				cornerW[c] = weights[site]
+				w += cornerW[c]
+			}
+			w /= float64(dim + 1)
+			sort.Float64s(corner)
+			for i, omega := range omegas {
+				if dim == 2 {
+					result[i] += w * volume * triangleDos(corner, omega)
+				} else {
+					result[i] += w * volume * tetrahedronDos(corner, omega)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// Density of states at omega for a triangle of unit area with sorted corner
+// energies e, normalized so that its integral over omega is 1.
+func triangleDos(e []float64, omega float64) float64 {
+	e1, e2, e3 := e[0], e[1], e[2]
+	if omega <= e1 || omega >= e3 || e3 == e1 {
+		return 0.0
+	}
+	if omega < e2 {
+		return 2.0 * (omega - e1) / ((e2 - e1) * (e3 - e1))
+	}
+	if e3 == e2 {
+		return 0.0
+	}
+	return 2.0 * (e3 - omega) / ((e3 - e1) * (e3 - e2))
+}
+
+// Density of states at omega for a tetrahedron of unit volume with sorted
+// corner energies e, normalized so that its integral over omega is 1.
+func tetrahedronDos(e []float64, omega float64) float64 {
+	e1, e2, e3, e4 := e[0], e[1], e[2], e[3]
+	if omega <= e1 || omega >= e4 || e4 == e1 {
+		return 0.0
+	}
+	if omega < e2 {
+		return 3.0 * (omega - e1) * (omega - e1) / ((e2 - e1) * (e3 - e1) * (e4 - e1))
+	}
+	if omega < e3 {
+		x := omega - e2
+		return 3.0 / ((e3 - e1) * (e4 - e1)) * (e2 - e1 + 2.0*x - (e3-e1+e4-e2)*x*x/((e3-e2)*(e4-e2)))
+	}
+	if e4 == e3 {
+		return 0.0
+	}
+	return 3.0 * (e4 - omega) * (e4 - omega) / ((e4 - e1) * (e4 - e2) * (e4 - e3))
+}
@@ -0,0 +1,88 @@
+package dos
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+func dosTestEnv() (*tempAll.Environment, error) {
+	return tempAll.NewEnvironment(`{"PointsPerSide": 32, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 10.0, "Mu_h": -0.5, "F0": 0.1}`)
+}
+
+// Integrate dos over evenly spaced omegas with the trapezoid rule.
+func integrateDos(omegas, dos []float64) float64 {
+	step := omegas[1] - omegas[0]
+	total := 0.0
+	for i := range dos {
+		if i == 0 || i == len(dos)-1 {
+			total += dos[i] / 2.0
+		} else {
+			total += dos[i]
+		}
+	}
+	return total * step
+}
+
+// The holon density of states should integrate to 1, in two and three
+// dimensions, with and without broadening.
+func TestHolonNormalization(t *testing.T) {
+	env, err := dosTestEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env3, err := tempAll.NewEnvironment(`{"PointsPerSide": 12, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Dispersion": "extended", "HolonTz": 0.2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		env *tempAll.Environment
+		b   Broadening
+	}{
+		{env, Broadening{}},
+		{env3, Broadening{}},
+		{env, Broadening{Kind: "gaussian", Width: 0.05}},
+	}
+	// The interpolated density of states has sharp peaks from nearly flat
+	// triangles, so the trapezoid rule needs a fine omega grid.
+	for _, c := range cases {
+		omegas := Omegas(-1.0, 10.0, 20001)
+		N, err := Holon(c.env, omegas, c.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		total := integrateDos(omegas, N)
+		if math.Abs(total-1.0) > 1e-3 {
+			t.Fatalf("holon density of states integrates to %v (HolonDim = %d, broadening %v)", total, c.env.HolonDim(), c.b)
+		}
+	}
+}
+
+// With F0 = 0, the quasiparticle density of states should equal the holon
+// density of states shifted by the holon chemical potential.
+func TestQuasiparticleNormalState(t *testing.T) {
+	env, err := dosTestEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.F0 = 0.0
+	b := Broadening{Kind: "lorentzian", Width: 0.1}
+	omegas := Omegas(-2.0, 8.0, 41)
+	Nqp, err := Quasiparticle(env, omegas, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shifted := make([]float64, len(omegas))
+	for i, omega := range omegas {
+		shifted[i] = omega + env.Mu_h
+	}
+	Nh, err := Holon(env, shifted, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range omegas {
+		if math.Abs(Nqp[i]-Nh[i]) > 1e-9 {
+			t.Fatalf("quasiparticle DOS %v differs from holon DOS %v at omega = %v", Nqp[i], Nh[i], omegas[i])
+		}
+	}
+}
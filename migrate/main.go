@@ -0,0 +1,49 @@
+// Command migrate rewrites Environment input files and solution caches
+// written by older versions of scExplorer so that they use the current
+// Environment schema version.
+//
+// Usage:
+//
+//	migrate file1.json [file2.json ...]
+//
+// Each file is read, upgraded in place, and reported as migrated or
+// already current. Files are not rewritten if no migration is required.
+// Unlike when an Environment is loaded, a file with no SchemaVersion is
+// taken to have been written before versioning was introduced.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: migrate file [file ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	failed := false
+	for _, path := range flag.Args() {
+		changed, err := tempAll.MigrateFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		if changed {
+			fmt.Printf("%s: migrated to schema version %d\n", path, tempAll.SCHEMA_VERSION)
+		} else {
+			fmt.Printf("%s: already at schema version %d\n", path, tempAll.SCHEMA_VERSION)
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,187 @@
+// Optical conductivity of the holons from the Kubo formula.
+//
+// The current vertex at q = 0 is the holon velocity v_x = dxi/dk_x, which is
+// diagonal in Nambu space, so
+// Re sigma(omega) = (pi/2) <v_x^2 int de [f(e) - f(e + omega)]/omega Tr[A(e) A(e + omega)]>_k,
+// where A(k, e) is the Nambu spectral function built from the Bogolyubov
+// coherence factors A_k = u_k^2 and B_k = v_k^2 (as in tempCrit.Pi) and a
+// broadened delta function. Holons are spinless, so the Nambu trace counts
+// each state twice (as k and -k); the factor 1/2 removes the double count.
+// Conductivities are per plane in units of e^2/hbar.
+package optics
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempLow"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Number of energy steps per broadening width (or per temperature, if
+// smaller) in the frequency convolution.
+const STEPS_PER_WIDTH = 8
+
+// Real part of the optical conductivity sigma_xx(omega) at each omega in
+// omegas, which must be positive. b gives the quasiparticle broadening and
+// must have positive width.
+func Conductivity(env *tempAll.Environment, omegas []float64, b dos.Broadening) ([]float64, error) {
+	if b.Width <= 0.0 {
+		return nil, fmt.Errorf("optical conductivity requires positive broadening width; got %v", b.Width)
+	}
+	kernel, err := b.Kernel()
+	if err != nil {
+		return nil, err
+	}
+	for _, omega := range omegas {
+		if omega <= 0.0 {
+			return nil, fmt.Errorf("optical conductivity requires omega > 0; got %v", omega)
+		}
+	}
+	// f(e) - f(e + omega) is negligible outside [-omega - cut, cut]
+	cut, step := 0.0, b.Width/STEPS_PER_WIDTH
+	if !math.IsInf(env.Beta, 1) {
+		cut = 20.0 / env.Beta
+		step = math.Min(step, 1.0/(env.Beta*STEPS_PER_WIDTH))
+	}
+	result := make([]float64, len(omegas))
+	for i, omega := range omegas {
+		inner := func(k vec.Vector) float64 {
+			vx := tempAll.FermiVelocity(env, k)[0]
+			E, u2 := env.BogoEnergy(k), env.CoherenceU2(k)
+			v2 := 1.0 - u2
+			uv2 := u2 * v2
+			lo, hi := -omega-cut, cut
+			N := int(math.Ceil((hi - lo) / step))
+			de := (hi - lo) / float64(N)
+			total := 0.0
+			for n := 0; n < N; n++ {
+				e := lo + (float64(n)+0.5)*de
+				thermal := env.Fermi(e) - env.Fermi(e+omega)
+				L1, L2 := kernel(e-E), kernel(e+E)
+				M1, M2 := kernel(e+omega-E), kernel(e+omega+E)
+				a11, a22, a12 := u2*L1+v2*L2, v2*L1+u2*L2, L1-L2
+				b11, b22, b12 := u2*M1+v2*M2, v2*M1+u2*M2, M1-M2
+				total += thermal * (a11*b11 + a22*b22 + 2.0*uv2*a12*b12)
+			}
+			return vx * vx * total * de / omega
+		}
+		result[i] = math.Pi / 2.0 * bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+	}
+	return result, nil
+}
+
+// Drude weight of thermally excited quasiparticles in the clean limit,
+// D_n = pi <v_x^2 (-df/dE)>_k; the coefficient of delta(omega) in
+// Re sigma(omega) from quasiparticle scattering. This is the full Drude
+// weight in the normal state.
+func DrudeWeight(env *tempAll.Environment) float64 {
+	if math.IsInf(env.Beta, 1) {
+		return 0.0
+	}
+	inner := func(k vec.Vector) float64 {
+		vx := tempAll.FermiVelocity(env, k)[0]
+		f := env.Fermi(env.BogoEnergy(k))
+		return vx * vx * env.Beta * f * (1.0 - f)
+	}
+	return math.Pi * bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+}
+
+// Holon part of the superfluid weight, D_s = pi rho_qp (see
+// tempLow.QuasiparticleStiffness): the coefficient of delta(omega) in the
+// holon Re sigma(omega). The condensed pairs carry a further weight
+// pi rho_pair (tempLow.PairStiffness), which is not part of the holon f-sum
+// rule and so is not included here.
+func SuperfluidWeight(env *tempAll.Environment) float64 {
+	return math.Pi * tempLow.QuasiparticleStiffness(env, 0)
+}
+
+// Comparison of the integrated conductivity with the f-sum rule
+// int_0^infinity Re sigma(omega) d omega = (pi/2) <d^2xi/dk_x^2 n_k/2>_k,
+// where n_k/2 is the occupation of a spinless holon state (see
+// tempAll.Environment.HolonOccupation).
+//
+// For hopping only between nearest neighbors along x, d^2xi/dk_x^2 =
+// -epsilon_h(k) and the weight is -(pi/2) U_1, with U_1 = <epsilon_h n_k/2>_k
+// the holon kinetic energy (tempCrit.HolonEnergy in the normal state). The
+// holon dispersion also has hopping of range 2 along x, hopping
+// perpendicular to x and a constant offset, so U_1 is corrected by
+// C = <(epsilon_h + d^2epsilon_h/dk_x^2) n_k/2>_k, which vanishes for
+// nearest-neighbor hopping.
+type SumRule struct {
+	Regular    float64 // integral of Conductivity over the given frequencies
+	Delta      float64 // D_s/2, from the superfluid delta function at omega = 0
+	Expected   float64 // (pi/2) <d^2xi/dk_x^2 n_k/2>_k
+	Kinetic    float64 // holon kinetic energy U_1
+	Correction float64 // C, the part of U_1 not from nearest-neighbor hopping along x
+}
+
+// Weight expected from the kinetic energy, -(pi/2) (U_1 - C).
+func (s *SumRule) KineticWeight() float64 {
+	return -math.Pi / 2.0 * (s.Kinetic - s.Correction)
+}
+
+func (s *SumRule) String() string {
+	total := s.Regular + s.Delta
+	return fmt.Sprintf("sum rule: regular %g + delta %g = %g; expected %g (relative error %g); from kinetic energy %g - correction %g: %g", s.Regular, s.Delta, total, s.Expected, (total-s.Expected)/s.Expected, s.Kinetic, s.Correction, s.KineticWeight())
+}
+
+// Check the f-sum rule for Re sigma(omega) found by Conductivity with
+// broadening b on the evenly spaced frequencies omegas, which should extend
+// well beyond the bandwidth. With broadening the quasiparticle Drude peak is
+// part of sigma(omega), so only the superfluid weight is added separately.
+// The sum rule holds as the broadening width goes to zero, for a grid fine
+// enough to resolve the Fermi function.
+func CheckSumRule(env *tempAll.Environment, omegas []float64, b dos.Broadening) (*SumRule, error) {
+	if len(omegas) < 2 {
+		return nil, fmt.Errorf("sum rule check needs at least 2 frequencies")
+	}
+	sigma, err := Conductivity(env, omegas, b)
+	if err != nil {
+		return nil, err
+	}
+	report := &SumRule{}
+	// trapezoid rule, extended linearly from omega = 0 to omegas[0]
+	step := omegas[1] - omegas[0]
+	for i := range sigma {
+		w := step
+		if i == 0 {
+			w = step/2.0 + omegas[0]
+		} else if i == len(sigma)-1 {
+			w = step / 2.0
+		}
+		report.Regular += w * sigma[i]
+	}
+	report.Delta = SuperfluidWeight(env) / 2.0
+	report.Expected = math.Pi / 2.0 * diamagnetic(env)
+	report.Kinetic, report.Correction = kineticEnergy(env)
+	return report, nil
+}
+
+// <d^2xi/dk_x^2 n_k/2>_k.
+func diamagnetic(env *tempAll.Environment) float64 {
+	inner := func(k vec.Vector) float64 {
+		_, d2 := tempAll.XiDerivatives(env, k, 0)
+		return d2 * env.HolonOccupation(k) / 2.0
+	}
+	return bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+}
+
+// Holon kinetic energy U_1 = <epsilon_h n_k/2>_k and its part
+// C = <(epsilon_h + d^2epsilon_h/dk_x^2) n_k/2>_k not from nearest-neighbor
+// hopping along x (see SumRule).
+func kineticEnergy(env *tempAll.Environment) (float64, float64) {
+	inner := func(k vec.Vector, out *vec.Vector) {
+		_, d2 := tempAll.XiDerivatives(env, k, 0)
+		n := env.HolonOccupation(k) / 2.0
+		eps := env.Epsilon_h(k)
+		(*out)[0] = eps * n
+		(*out)[1] = (eps + d2) * n
+	}
+	avg := bzone.VectorAvg(env.PointsPerSide, env.HolonDim(), 2, inner)
+	return avg[0], avg[1]
+}
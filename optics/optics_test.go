@@ -0,0 +1,70 @@
+package optics
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+)
+
+// In the normal state, the integrated conductivity should satisfy the f-sum
+// rule, also when found from the kinetic energy, and the Drude weight should
+// be the full expected weight.
+func TestNormalStateSumRule(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 32, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 5.0, "Mu_h": 0.5}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	omegas := dos.Omegas(0.025, 10.0, 200)
+	report, err := CheckSumRule(env, omegas, dos.Broadening{Kind: "gaussian", Width: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report)
+	total := report.Regular + report.Delta
+	if math.Abs(total-report.Expected) > 0.1*math.Abs(report.Expected) {
+		t.Fatalf("sum rule violated: %v", report)
+	}
+	if math.Abs(total-report.KineticWeight()) > 0.1*math.Abs(report.Expected) {
+		t.Fatalf("sum rule from kinetic energy violated: %v", report)
+	}
+	U1, err := tempCrit.HolonEnergy(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(report.Kinetic-U1) > 1e-12 {
+		t.Fatalf("kinetic energy %v differs from tempCrit.HolonEnergy %v", report.Kinetic, U1)
+	}
+	if D := DrudeWeight(env); math.Abs(D/2.0-report.Expected) > 5e-3*math.Abs(report.Expected) {
+		t.Fatalf("normal-state Drude weight %v differs from 2 * expected weight %v", D, report.Expected)
+	}
+}
+
+// In the superconducting state, the regular conductivity and the superfluid
+// weight together should satisfy the f-sum rule, and the superfluid weight
+// should be a significant part of it.
+func TestSuperconductingSumRule(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 32, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 5.0, "Mu_h": 0.5, "F0": 0.05}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	omegas := dos.Omegas(0.025, 10.0, 200)
+	report, err := CheckSumRule(env, omegas, dos.Broadening{Kind: "gaussian", Width: 0.1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report)
+	total := report.Regular + report.Delta
+	if math.Abs(total-report.Expected) > 0.1*math.Abs(report.Expected) {
+		t.Fatalf("sum rule violated: %v", report)
+	}
+	if math.Abs(total-report.KineticWeight()) > 0.1*math.Abs(report.Expected) {
+		t.Fatalf("sum rule from kinetic energy violated: %v", report)
+	}
+	if report.Delta < 0.1*report.Expected {
+		t.Fatalf("superfluid weight %v is small compared to expected weight %v", 2.0*report.Delta, report.Expected)
+	}
+}
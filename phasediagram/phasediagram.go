@@ -0,0 +1,261 @@
+// Phase diagrams in the temperature-doping (T-x) plane.
+package phasediagram
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+)
+import (
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/serialize"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempFluc"
+	"github.com/tflovorn/scExplorer/tempPair"
+	"github.com/tflovorn/scExplorer/units"
+)
+
+// Regions of the phase diagram.
+type Region int
+
+const (
+	Superconducting Region = iota // T < T_c
+	Pseudogap                     // T_c <= T < T_p: pairs present, no condensate
+	Normal                        // T >= T_p
+)
+
+func (r Region) String() string {
+	switch r {
+	case Superconducting:
+		return "superconducting"
+	case Pseudogap:
+		return "pseudogap"
+	case Normal:
+		return "normal"
+	}
+	return fmt.Sprintf("Region(%d)", int(r))
+}
+
+// Return the region containing temperature T given T_c and T_p.
+func Classify(T, Tc, Tp float64) Region {
+	if T < Tc {
+		return Superconducting
+	} else if T < Tp {
+		return Pseudogap
+	}
+	return Normal
+}
+
+// T_c and T_p as functions of x for fixed hopping parameters. Tc[i] and
+// Tp[i] are NaN if no solution was found at Xs[i].
+type PhaseDiagram struct {
+	Xs     []float64
+	Tc, Tp []float64
+	// Tc(x) at each nonzero magnetic field in BeFields; TcB[j][i] is
+	// T_c at Xs[i] with Be_field = BeFields[j].
+	BeFields []float64
+	TcB      [][]float64
+	// Environment giving the hopping parameters and physical energy scale;
+	// nil if pd was not made by Generate.
+	env *tempAll.Environment
+}
+
+// Options for Generate.
+type Options struct {
+	// Magnetic fields (Be_field) at which to find T_c(x). May be empty.
+	BeFields []float64
+	// Solver used to find T_c at finite field. If nil,
+	// tempFluc.CritTempFieldSolve is used.
+	FieldTcSolver tempAll.Solver
+}
+
+// Find T_p(x) and T_c(x) for each x in Xs, using the hopping parameters and
+// starting guesses given by env. If any point could not be solved, the
+// returned error describes each failure; pd is returned in either case, with
+// NaN at the failed points.
+func Generate(env *tempAll.Environment, Xs []float64, opts Options, epsAbs, epsRel float64) (*PhaseDiagram, error) {
+	if opts.FieldTcSolver == nil {
+		opts.FieldTcSolver = tempFluc.CritTempFieldSolve
+	}
+	pd := &PhaseDiagram{Xs: Xs, BeFields: opts.BeFields, env: env.Copy()}
+	failures := []string{}
+	var fs []string
+	pd.Tp, fs = solveTemps(splitX(env, Xs, 0.0), epsAbs, epsRel, tempPair.PairTempSolve)
+	failures = append(failures, labelFailures("T_p", fs)...)
+	pd.Tc, fs = solveTemps(splitX(env, Xs, 0.0), epsAbs, epsRel, tempCrit.CritTempSolve)
+	failures = append(failures, labelFailures("T_c", fs)...)
+	pd.TcB = make([][]float64, len(opts.BeFields))
+	for j, Be := range opts.BeFields {
+		pd.TcB[j], fs = solveTemps(splitX(env, Xs, Be), epsAbs, epsRel, opts.FieldTcSolver)
+		failures = append(failures, labelFailures(fmt.Sprintf("T_c(Be_field = %v)", Be), fs)...)
+	}
+	if len(failures) != 0 {
+		return pd, fmt.Errorf("phase diagram incomplete: %s", strings.Join(failures, "; "))
+	}
+	return pd, nil
+}
+
+// Prefix each of failures with the name of the temperature being solved for.
+func labelFailures(name string, failures []string) []string {
+	ret := make([]string, len(failures))
+	for i, f := range failures {
+		ret[i] = name + " at " + f
+	}
+	return ret
+}
+
+// Create a copy of env for each x in Xs, ready to solve for T_c or T_p.
+func splitX(env *tempAll.Environment, Xs []float64, Be_field float64) []*tempAll.Environment {
+	envs := make([]*tempAll.Environment, len(Xs))
+	for i, x := range Xs {
+		envs[i] = env.Copy()
+		envs[i].X = x
+		envs[i].F0 = 0.0
+		envs[i].Mu_b = 0.0
+		envs[i].Be_field = Be_field
+	}
+	return envs
+}
+
+// Solve each of envs with sv and return the resulting temperatures (NaN
+// for those which could not be solved), along with a description of each
+// failure.
+func solveTemps(envs []*tempAll.Environment, epsAbs, epsRel float64, sv tempAll.Solver) ([]float64, []string) {
+	solved, errs := tempAll.MultiSolve(envs, epsAbs, epsRel, sv)
+	temps := make([]float64, len(envs))
+	failures := []string{}
+	for i, s := range solved {
+		if errs[i] != nil {
+			temps[i] = math.NaN()
+			failures = append(failures, fmt.Sprintf("x = %v: %v", envs[i].X, errs[i]))
+			continue
+		}
+		env := s.(tempAll.Environment)
+		temps[i] = 1.0 / env.Beta
+	}
+	return temps, failures
+}
+
+// Region at each (x, T) with x in pd.Xs and T in temps (in zero field).
+// The result is indexed as [i][j] for x = pd.Xs[i] and T = temps[j].
+// Points for which T_c or T_p is unknown are labeled Normal.
+func (pd *PhaseDiagram) Regions(temps []float64) [][]Region {
+	regions := make([][]Region, len(pd.Xs))
+	for i := range pd.Xs {
+		regions[i] = make([]Region, len(temps))
+		for j, T := range temps {
+			if math.IsNaN(pd.Tc[i]) || math.IsNaN(pd.Tp[i]) {
+				regions[i][j] = Normal
+				continue
+			}
+			regions[i][j] = Classify(T, pd.Tc[i], pd.Tp[i])
+		}
+	}
+	return regions
+}
+
+// Write pd to path as JSON.
+func (pd *PhaseDiagram) Save(path string) error {
+	marshalled, err := serialize.MakeJSON(pd.jsonSafe())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(marshalled), os.ModePerm)
+}
+
+// Copy of pd with NaN (not representable in JSON) replaced by -1.
+func (pd *PhaseDiagram) jsonSafe() PhaseDiagram {
+	fix := func(xs []float64) []float64 {
+		ret := make([]float64, len(xs))
+		for i, x := range xs {
+			if math.IsNaN(x) {
+				ret[i] = -1.0
+			} else {
+				ret[i] = x
+			}
+		}
+		return ret
+	}
+	safe := PhaseDiagram{Xs: pd.Xs, Tc: fix(pd.Tc), Tp: fix(pd.Tp), BeFields: pd.BeFields}
+	safe.TcB = make([][]float64, len(pd.TcB))
+	for j, TcB := range pd.TcB {
+		safe.TcB[j] = fix(TcB)
+	}
+	return safe
+}
+
+// Make a filled-region plot of the zero-field phase diagram, plus T_c(x) at
+// each finite field as separate line plots. x values at which T_c or T_p is
+// unknown are skipped. params[plots.FILE_KEY] gives the output path prefix.
+// Temperatures are given in K if the Environment passed to Generate has a
+// physical energy scale (see units.HasPhysicalScale); the axis labels are
+// set unless params already gives them.
+func (pd *PhaseDiagram) Plot(params map[string]string, grapherPath string) error {
+	scaleEnv := pd.env
+	if scaleEnv == nil {
+		scaleEnv = new(tempAll.Environment)
+	}
+	if _, ok := params[plots.XLABEL_KEY]; !ok {
+		params[plots.XLABEL_KEY] = "$x$"
+	}
+	if _, ok := params[plots.YLABEL_KEY]; !ok {
+		params[plots.YLABEL_KEY] = units.TempLabel(scaleEnv, "T")
+	}
+	physical := func(T float64) float64 {
+		if !units.HasPhysicalScale(scaleEnv) {
+			return T
+		}
+		TK, _ := units.Temperature(scaleEnv, T)
+		return TK
+	}
+	xs, Tcs, Tps := []float64{}, []float64{}, []float64{}
+	top := 0.0
+	for i, x := range pd.Xs {
+		if math.IsNaN(pd.Tc[i]) || math.IsNaN(pd.Tp[i]) {
+			continue
+		}
+		xs = append(xs, x)
+		Tcs = append(Tcs, physical(pd.Tc[i]))
+		Tps = append(Tps, physical(pd.Tp[i]))
+		top = math.Max(top, physical(pd.Tp[i]))
+	}
+	if len(xs) < 2 {
+		return fmt.Errorf("not enough solved points to plot phase diagram")
+	}
+	regionParams := []map[string]string{
+		map[string]string{"label": Superconducting.String(), "color": "b"},
+		map[string]string{"label": Pseudogap.String(), "color": "g"},
+		map[string]string{"label": Normal.String(), "color": "w"},
+	}
+	err := plots.PlotFilledRegions(xs, [][]float64{Tcs, Tps}, 1.2*top, params, regionParams, grapherPath)
+	if err != nil {
+		return err
+	}
+	if len(pd.BeFields) == 0 {
+		return nil
+	}
+	// T_c(x) at each field, with zero field for comparison
+	series := []plots.Series{plots.MakeSeries(xs, Tcs)}
+	fields := []float64{0.0}
+	for j, Be := range pd.BeFields {
+		fxs, fTcs := []float64{}, []float64{}
+		for i, x := range pd.Xs {
+			if !math.IsNaN(pd.TcB[j][i]) {
+				fxs = append(fxs, x)
+				fTcs = append(fTcs, physical(pd.TcB[j][i]))
+			}
+		}
+		series = append(series, plots.MakeSeries(fxs, fTcs))
+		fields = append(fields, Be)
+	}
+	fieldParams := make(map[string]string)
+	for k, v := range params {
+		fieldParams[k] = v
+	}
+	fieldParams[plots.FILE_KEY] = params[plots.FILE_KEY] + "_TcB"
+	seriesParams := plots.MakeSeriesParams("eB", "%.3f", fields, plots.DEFAULT_STYLES)
+	return plots.PlotMPL(series, fieldParams, seriesParams, grapherPath)
+}
@@ -0,0 +1,92 @@
+package phasediagram
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+var testPlot = flag.Bool("testPlot", false, "Run tests involving plots")
+
+// Regions should be split at T_c and T_p, with unknown points normal.
+func TestRegions(t *testing.T) {
+	pd := &PhaseDiagram{Xs: []float64{0.05, 0.1}, Tc: []float64{0.1, math.NaN()}, Tp: []float64{0.3, 0.4}}
+	regions := pd.Regions([]float64{0.05, 0.1, 0.2, 0.35})
+	expected := [][]Region{
+		[]Region{Superconducting, Pseudogap, Pseudogap, Normal},
+		[]Region{Normal, Normal, Normal, Normal},
+	}
+	for i := range expected {
+		for j := range expected[i] {
+			if regions[i][j] != expected[i][j] {
+				t.Fatalf("incorrect region %v at (%d, %d); expected %v", regions[i][j], i, j, expected[i][j])
+			}
+		}
+	}
+}
+
+// Points which could not be solved should be NaN and reported.
+func TestSolveTempsFailures(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 4.0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sv := func(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector, error) {
+		if env.X > 0.1 {
+			return nil, fmt.Errorf("no solution")
+		}
+		return nil, nil
+	}
+	temps, failures := solveTemps(splitX(env, []float64{0.05, 0.15}, 0.0), 1e-9, 1e-9, sv)
+	if temps[0] != 0.25 || !math.IsNaN(temps[1]) {
+		t.Fatalf("incorrect temperatures %v", temps)
+	}
+	if len(failures) != 1 || failures[0] != "x = 0.15: no solution" {
+		t.Fatalf("incorrect failures %v", failures)
+	}
+}
+
+// Plot the T-x phase diagram.
+func TestPlotPhaseDiagram(t *testing.T) {
+	flag.Parse()
+	if !*testPlot {
+		return
+	}
+	data, err := ioutil.ReadFile("../tempCrit/system_test_env.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := tempAll.NewEnvironment(string(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	N := 10
+	Xs := make([]float64, N)
+	for i := 0; i < N; i++ {
+		Xs[i] = 0.02 + 0.13*float64(i)/float64(N-1)
+	}
+	eps := 1e-6
+	pd, err := Generate(env, Xs, Options{}, eps, eps)
+	if err != nil {
+		// unsolved points are left out of the plot
+		t.Log(err)
+	}
+	wd, _ := os.Getwd()
+	err = pd.Save(wd + "/plot_data.phase_diagram_data.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := map[string]string{plots.FILE_KEY: wd + "/plot_data.phase_diagram"}
+	err = pd.Plot(params, wd+"/../plots/grapher.py")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,35 @@
+package plots
+
+import "fmt"
+
+const PLOT_TYPE_KEY = "plot_type"
+const FILLED_REGIONS_TYPE = "filled_regions"
+const FILL_TOP_KEY = "fill_top"
+
+// Plot filled regions separated by the curves boundaries[i](xs), which must
+// be ordered from bottom to top. Region 0 lies between y = 0 and
+// boundaries[0]; region i lies between boundaries[i-1] and boundaries[i];
+// the last region lies between the top boundary and y = top.
+// regionParams[i] gives the parameters ("label", "color") for region i, so
+// len(regionParams) must be len(boundaries)+1.
+func PlotFilledRegions(xs []float64, boundaries [][]float64, top float64, params map[string]string, regionParams []map[string]string, grapherPath string) error {
+	if len(regionParams) != len(boundaries)+1 {
+		return fmt.Errorf("need %d region parameter sets; got %d", len(boundaries)+1, len(regionParams))
+	}
+	data := make([]Series, len(boundaries)+1)
+	for i, ys := range boundaries {
+		if len(ys) != len(xs) {
+			return fmt.Errorf("boundary %d has %d values; expected %d", i, len(ys), len(xs))
+		}
+		data[i] = MakeSeries(xs, ys)
+	}
+	// the last region has no boundary curve of its own
+	data[len(boundaries)] = MakeSeries([]float64{}, []float64{})
+	fillParams := make(map[string]string)
+	for k, v := range params {
+		fillParams[k] = v
+	}
+	fillParams[PLOT_TYPE_KEY] = FILLED_REGIONS_TYPE
+	fillParams[FILL_TOP_KEY] = fmt.Sprintf("%f", top)
+	return PlotMPL(data, fillParams, regionParams, grapherPath)
+}
@@ -1,19 +1,18 @@
 import sys
 import json
-import math
 import matplotlib.pyplot as plt
 from matplotlib.ticker import FormatStrFormatter
 from matplotlib.font_manager import FontProperties
-from numpy import arange, meshgrid
+from numpy import meshgrid
 
 _GRAPH_DEFAULTS = {"xlabel":"$x$", "ylabel":"$y$", "num_ticks":5, 
     "axis_label_fontsize":"x-large", "tick_formatstr":"%.2f",
     "legend_fontsize":"large", "legend_loc":0, "legend_title":None, 
     "ymin":None, "xmax":None, "graph_filepath":None, "plot_type": "scatter",
-    "delta":"0.05",
-    "th":None,"thp":None,"t0":None,"D1":None,"Mu_h":None,"epsilon_min":None}
+    "fill_top":None, "colormap":"viridis", "zlabel":None,
+    "xticks":None, "xticklabels":None}
 
-_SERIES_DEFAULTS = {"label":None, "style":"k."}
+_SERIES_DEFAULTS = {"label":None, "style":"k.", "color":None}
 
 def parse_file(file_path):
     '''Return the plot representation of the JSON file specified.'''
@@ -53,10 +52,13 @@ def make_graph(graph_data):
     # Process a list of graphs one element at a time.
     if isinstance(graph_data, list):
         return [make_graph(some_graph) for some_graph in graph_data]
-    # If we need to make a Fermi surface plot, go to the function for that.
-    if graph_data["plot_type"] == "Fermi_surface":
-        return plot_Fermi_surface(graph_data)
-    # If we get here, this is not a Fermi surface plot - make scatter plot instead.
+    # Filled regions between boundary curves (e.g. a phase diagram).
+    if graph_data["plot_type"] == "filled_regions":
+        return plot_filled_regions(graph_data)
+    # Color map of z over an (x, y) grid (e.g. an ARPES intensity map).
+    if graph_data["plot_type"] == "heatmap":
+        return plot_heatmap(graph_data)
+    # Otherwise make a scatter plot.
     try:
         dims = graph_data["dimensions"]
         fig = plt.figure(figsize=(dims[0], dims[1]))
@@ -78,6 +80,7 @@ def make_graph(graph_data):
         axes.set_xlim(right=float(graph_data["xmax"]), auto=None)
     if graph_data["ymin"] != None and graph_data["ymin"] != "":
         axes.set_ylim(bottom=float(graph_data["ymin"]), auto=None)
+    _set_xticks(graph_data, axes)
     _save_figure(graph_data, fig)
     return fig, axes
 
@@ -93,45 +96,72 @@ def _xData(series):
 def _yData(series):
     return [point[1] for point in series["data"]]
 
+# Place labeled x ticks (e.g. high-symmetry points), marked by vertical lines.
+def _set_xticks(graph_data, axes):
+    if graph_data["xticks"] is None or graph_data["xticks"] == "":
+        return
+    ticks = [float(x) for x in graph_data["xticks"].split(",")]
+    axes.set_xticks(ticks)
+    axes.set_xticklabels(graph_data["xticklabels"].split(","))
+    for x in ticks:
+        axes.axvline(x, color="0.5", linewidth=0.5)
+    axes.set_xlim(left=ticks[0], right=ticks[-1])
+
 def _save_figure(graph_data, fig):
     if graph_data["graph_filepath"] is None:
         return
     fig.savefig(graph_data["graph_filepath"] + ".png", bbox_inches="tight", dpi=200)
     fig.savefig(graph_data["graph_filepath"] + ".eps", bbox_inches="tight", dpi=200)
 
-# Plot a single Fermi surface.
-# To make this plot, graph_data["Fermi_surface_data"] must be a dictionary
-# with the keys "th", "thp", "t0", "D1", "Mu_h", and "epsilon_min".
-def plot_Fermi_surface(graph_data):
-    delta = float(graph_data["delta"])
-    x = arange(-math.pi, math.pi, delta)
-    y = arange(-math.pi, math.pi, delta)
-    X, Y = meshgrid(x, y)
-    FS = lambda x, y: _step(_xi_h(graph_data, x, y))
-    Z = []
-    for i in range(len(y)):
-        Z.append([])
-        for j in range(len(x)):
-            Z[i].append(FS(x[j], y[i]))
+# Plot regions between boundary curves. All series except the last give the
+# boundary curves from bottom to top, which must share the same x values.
+# Series i is filled from the previous boundary (or 0) up to its own curve;
+# the last series is filled from the top boundary up to graph_data["fill_top"].
+def plot_filled_regions(graph_data):
     fig = plt.figure()
     axes = fig.add_subplot(1, 1, 1)
+    series_list = graph_data["series"]
+    xs = _xData(series_list[0])
+    lower = [0.0 for x in xs]
+    for series in series_list[:-1]:
+        upper = _yData(series)
+        axes.fill_between(xs, lower, upper, color=series["color"],
+                          label=series["label"], alpha=0.6)
+        axes.plot(xs, upper, "k-")
+        lower = upper
+    top = float(graph_data["fill_top"])
+    last = series_list[-1]
+    axes.fill_between(xs, lower, [top for x in xs], color=last["color"],
+                      label=last["label"], alpha=0.6)
+    fontprop_legend = FontProperties(size=graph_data["legend_fontsize"])
+    axes.legend(loc=graph_data["legend_loc"], title=graph_data["legend_title"],
+                prop=fontprop_legend)
     axes.set_xlabel(graph_data["xlabel"], size=graph_data["axis_label_fontsize"])
     axes.set_ylabel(graph_data["ylabel"], size=graph_data["axis_label_fontsize"])
-    CS = axes.contour(X, Y, Z)
+    axes.set_xlim(left=xs[0], right=xs[-1])
+    axes.set_ylim(bottom=0.0, top=top)
     _save_figure(graph_data, fig)
+    return fig, axes
 
-def _xi_h(fsd, kx, ky):
-    sx, sy = math.sin(kx), math.sin(ky)
-    envVars = map(float, [fsd["th"], fsd["D1"], fsd["t0"], fsd["thp"], fsd["epsilon_min"], fsd["Mu_h"]])
-    th, D1, t0, thp, epsilon_min, Mu_h = envVars
-    eps = 2.0*th*((sx+sy)*(sx+sy) - 1.0) + 4.0*(2.0*D1*t0 - thp)*sx*sy - epsilon_min
-    return eps - Mu_h
-
-def _step(x):
-    if x < 0.0:
-        return 0.0
-    else:
-        return 1.0
+# Plot a color map. Each series is one row of the map: its data gives (x, z)
+# pairs for the y value in series["y"]. All rows must share the same x values.
+def plot_heatmap(graph_data):
+    fig = plt.figure()
+    axes = fig.add_subplot(1, 1, 1)
+    series_list = graph_data["series"]
+    xs = _xData(series_list[0])
+    ys = [float(series["y"]) for series in series_list]
+    Z = [_yData(series) for series in series_list]
+    X, Y = meshgrid(xs, ys)
+    mesh = axes.pcolormesh(X, Y, Z, cmap=graph_data["colormap"], shading="auto")
+    colorbar = fig.colorbar(mesh, ax=axes)
+    if graph_data["zlabel"] is not None:
+        colorbar.set_label(graph_data["zlabel"], size=graph_data["axis_label_fontsize"])
+    axes.set_xlabel(graph_data["xlabel"], size=graph_data["axis_label_fontsize"])
+    axes.set_ylabel(graph_data["ylabel"], size=graph_data["axis_label_fontsize"])
+    _set_xticks(graph_data, axes)
+    _save_figure(graph_data, fig)
+    return fig, axes
 
 if __name__ == "__main__":
     if len(sys.argv) > 1:
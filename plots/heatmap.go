@@ -0,0 +1,37 @@
+package plots
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const HEATMAP_TYPE = "heatmap"
+const HEATMAP_Y_KEY = "y"
+const COLORMAP_KEY = "colormap"
+const ZLABEL_KEY = "zlabel"
+
+// Plot zs as a color map over the grid of xs and ys, with zs[j][i] the value
+// at (xs[i], ys[j]). Each row of zs is passed to the grapher as one series
+// whose parameter HEATMAP_Y_KEY gives its y value. params may set
+// COLORMAP_KEY (a matplotlib colormap name) and ZLABEL_KEY (the colorbar
+// label) in addition to the usual graph parameters.
+func PlotHeatmap(xs, ys []float64, zs [][]float64, params map[string]string, grapherPath string) error {
+	if len(zs) != len(ys) {
+		return fmt.Errorf("heatmap has %d rows; expected %d", len(zs), len(ys))
+	}
+	data := make([]Series, len(ys))
+	rowParams := make([]map[string]string, len(ys))
+	for j, row := range zs {
+		if len(row) != len(xs) {
+			return fmt.Errorf("heatmap row %d has %d values; expected %d", j, len(row), len(xs))
+		}
+		data[j] = MakeSeries(xs, row)
+		rowParams[j] = map[string]string{HEATMAP_Y_KEY: strconv.FormatFloat(ys[j], 'g', -1, 64)}
+	}
+	mapParams := make(map[string]string)
+	for k, v := range params {
+		mapParams[k] = v
+	}
+	mapParams[PLOT_TYPE_KEY] = HEATMAP_TYPE
+	return PlotMPL(data, mapParams, rowParams, grapherPath)
+}
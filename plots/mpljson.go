@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strconv"
+	"strings"
 )
 
 const FILE_KEY = "graph_filepath"
@@ -14,6 +16,8 @@ const DATA_KEY = "data"
 const XLABEL_KEY = "xlabel"
 const YLABEL_KEY = "ylabel"
 const YMIN_KEY = "ymin"
+const XTICKS_KEY = "xticks"
+const XTICKLABELS_KEY = "xticklabels"
 
 // A map compatible with the JSON object type
 type jsonObject map[string]interface{}
@@ -54,6 +58,17 @@ func MakeSeriesParams(varLabel, varFmt string, varVals []float64, styles []strin
 	return ret
 }
 
+// Set params to place x-axis ticks at positions ticks with the given labels
+// (e.g. high-symmetry points along a k-space path), marked by vertical lines.
+func SetXTicks(params map[string]string, ticks []float64, labels []string) {
+	tickStrs := make([]string, len(ticks))
+	for i, x := range ticks {
+		tickStrs[i] = strconv.FormatFloat(x, 'g', -1, 64)
+	}
+	params[XTICKS_KEY] = strings.Join(tickStrs, ",")
+	params[XTICKLABELS_KEY] = strings.Join(labels, ",")
+}
+
 // Write the graph data to the file specified in params[FILE_KEY]
 func graphDataToFile(data []Series, params map[string]string, seriesParams []map[string]string) (string, error) {
 	// place data in JSON object to be marshalled
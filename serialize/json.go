@@ -2,10 +2,41 @@ package serialize
 
 import (
 	"encoding/json"
+	"fmt"
+	"math"
 	"reflect"
 	"strings"
 )
 
+// Struct tag used to mark fields which must be present in strictly decoded
+// JSON data, as in:
+//
+//	X float64 `serialize:"required"`
+const TAG_KEY = "serialize"
+const REQUIRED_TAG = "required"
+
+// A problem found while strictly decoding a JSON object. Path gives the
+// location of the offending value, e.g. "Environment.Alpha" or "Xs[2]".
+type DecodeError struct {
+	Path   string
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Reason)
+}
+
+// All problems found while strictly decoding a JSON object.
+type DecodeErrors []*DecodeError
+
+func (es DecodeErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "invalid JSON data: " + strings.Join(msgs, "; ")
+}
+
 // Copy values from the JSON string given into object.
 func CopyFromJSON(jsonData string, object interface{}) error {
 	jsonObject, err := readJSONString(jsonData)
@@ -16,6 +47,18 @@ func CopyFromJSON(jsonData string, object interface{}) error {
 	return nil
 }
 
+// Copy values from the JSON string given into object. Unlike CopyFromJSON,
+// return an error (of type DecodeErrors) if jsonData contains a key which is
+// not a field of object, a value whose type does not match its field, or
+// lacks a field tagged as required.
+func CopyFromJSONStrict(jsonData string, object interface{}) error {
+	jsonObject, err := readJSONString(jsonData)
+	if err != nil {
+		return err
+	}
+	return CopyValuesStrict(jsonObject, object)
+}
+
 // Get a JSON object from the string given.
 func readJSONString(jsonData string) (*map[string]interface{}, error) {
 	jsonBytes, err := stringToBytes(jsonData)
@@ -71,6 +114,172 @@ func CopyValues(jsonObject *map[string]interface{}, object interface{}) {
 	}
 }
 
+// Copy each key in jsonObject into the corresponding field in object,
+// checking that every key names a field, every value has the type of its
+// field, and every required field is present. Fields are set for all valid
+// keys even if an error is returned.
+func CopyValuesStrict(jsonObject *map[string]interface{}, object interface{}) error {
+	objectValue := reflect.Indirect(reflect.ValueOf(object))
+	errs := copyStruct(objectValue.Type().Name(), *jsonObject, objectValue)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Copy jsonObject into the struct sv; path is the location of sv used in
+// error messages.
+func copyStruct(path string, jsonObject map[string]interface{}, sv reflect.Value) DecodeErrors {
+	errs := DecodeErrors{}
+	for key, value := range jsonObject {
+		field := sv.FieldByName(key)
+		if !field.CanSet() {
+			errs = append(errs, &DecodeError{joinPath(path, key), "unknown field"})
+			continue
+		}
+		errs = append(errs, setValue(joinPath(path, key), field, value)...)
+	}
+	// check that all required fields were given
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.Tag.Get(TAG_KEY) != REQUIRED_TAG {
+			continue
+		}
+		if _, ok := jsonObject[sf.Name]; !ok {
+			errs = append(errs, &DecodeError{joinPath(path, sf.Name), "missing required field"})
+		}
+	}
+	return errs
+}
+
+// Set field to value if value is of an appropriate type.
+func setValue(path string, field reflect.Value, value interface{}) DecodeErrors {
+	mismatch := func() DecodeErrors {
+		reason := fmt.Sprintf("cannot use %s as %v", jsonTypeName(value), field.Type())
+		return DecodeErrors{&DecodeError{path, reason}}
+	}
+	switch field.Kind() {
+	case reflect.Float32, reflect.Float64:
+		x, ok := value.(float64)
+		if !ok {
+			return mismatch()
+		}
+		field.SetFloat(x)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, ok := value.(float64)
+		if !ok || x != math.Trunc(x) {
+			return mismatch()
+		}
+		if field.OverflowInt(int64(x)) {
+			return DecodeErrors{&DecodeError{path, fmt.Sprintf("value %v overflows %v", x, field.Type())}}
+		}
+		field.SetInt(int64(x))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, ok := value.(float64)
+		if !ok || x != math.Trunc(x) || x < 0 {
+			return mismatch()
+		}
+		if field.OverflowUint(uint64(x)) {
+			return DecodeErrors{&DecodeError{path, fmt.Sprintf("value %v overflows %v", x, field.Type())}}
+		}
+		field.SetUint(uint64(x))
+	case reflect.Bool:
+		x, ok := value.(bool)
+		if !ok {
+			return mismatch()
+		}
+		field.SetBool(x)
+	case reflect.String:
+		x, ok := value.(string)
+		if !ok {
+			return mismatch()
+		}
+		field.SetString(x)
+	case reflect.Struct:
+		x, ok := value.(map[string]interface{})
+		if !ok {
+			return mismatch()
+		}
+		return copyStruct(path, x, field)
+	case reflect.Slice:
+		if value == nil {
+			// nil slices are marshalled as null
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		xs, ok := value.([]interface{})
+		if !ok {
+			return mismatch()
+		}
+		errs := DecodeErrors{}
+		slice := reflect.MakeSlice(field.Type(), len(xs), len(xs))
+		for i, x := range xs {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			errs = append(errs, setValue(elemPath, slice.Index(i), x)...)
+		}
+		field.Set(slice)
+		return errs
+	case reflect.Map:
+		if value == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		xs, ok := value.(map[string]interface{})
+		if !ok || field.Type().Key().Kind() != reflect.String {
+			return mismatch()
+		}
+		errs := DecodeErrors{}
+		m := reflect.MakeMap(field.Type())
+		for k, x := range xs {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			errs = append(errs, setValue(joinPath(path, k), elem, x)...)
+			m.SetMapIndex(reflect.ValueOf(k), elem)
+		}
+		field.Set(m)
+		return errs
+	case reflect.Interface:
+		if value != nil && !reflect.TypeOf(value).AssignableTo(field.Type()) {
+			return mismatch()
+		}
+		if value == nil {
+			field.Set(reflect.Zero(field.Type()))
+		} else {
+			field.Set(reflect.ValueOf(value))
+		}
+	default:
+		return DecodeErrors{&DecodeError{path, fmt.Sprintf("unsupported field type %v", field.Type())}}
+	}
+	return nil
+}
+
+// Name of the JSON type corresponding to a value produced by json.Unmarshal.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return fmt.Sprintf("number %v", value)
+	case string:
+		return fmt.Sprintf("string %q", value)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// Append key to path, separated by a '.' if path is non-empty.
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
 // Convert string to byte slice
 func stringToBytes(str string) ([]byte, error) {
 	reader := strings.NewReader(str)
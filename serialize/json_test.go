@@ -0,0 +1,68 @@
+package serialize
+
+import (
+	"strings"
+	"testing"
+)
+
+type jsonTestData struct {
+	X     float64 `serialize:"required"`
+	N     int
+	Flag  bool
+	Inner jsonTestInner
+	Xs    []float64
+}
+
+type jsonTestInner struct {
+	Y float64
+}
+
+// Valid data should be copied in full.
+func TestCopyFromJSONStrict(t *testing.T) {
+	data := new(jsonTestData)
+	err := CopyFromJSONStrict(`{"X": 0.5, "N": 3, "Flag": true, "Inner": {"Y": 2.0}, "Xs": [1, 2]}`, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.X != 0.5 || data.N != 3 || !data.Flag || data.Inner.Y != 2.0 || len(data.Xs) != 2 || data.Xs[1] != 2.0 {
+		t.Fatalf("incorrect values copied: %v", *data)
+	}
+}
+
+// A nil slice is marshalled as null and should be read back as nil.
+func TestCopyFromJSONStrictNull(t *testing.T) {
+	marshalled, err := MakeJSON(jsonTestData{X: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := new(jsonTestData)
+	err = CopyFromJSONStrict(marshalled, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.X != 0.5 || data.Xs != nil {
+		t.Fatalf("incorrect values copied: %v", *data)
+	}
+}
+
+// Unknown keys, mismatched types and missing required fields should each be
+// reported along with their path.
+func TestCopyFromJSONStrictErrors(t *testing.T) {
+	cases := map[string]string{
+		`{"X": 0.5, "Z": 1.0}`:              "jsonTestData.Z: unknown field",
+		`{"X": "0.5"}`:                      "jsonTestData.X: cannot use string",
+		`{"X": 0.5, "N": 1.5}`:              "jsonTestData.N: cannot use number",
+		`{"N": 1}`:                          "jsonTestData.X: missing required field",
+		`{"X": 0.5, "Inner": {"Q": 1.0}}`:   "jsonTestData.Inner.Q: unknown field",
+		`{"X": 0.5, "Xs": [1.0, "a", 2.0]}`: "jsonTestData.Xs[1]: cannot use string",
+	}
+	for jsonData, expected := range cases {
+		err := CopyFromJSONStrict(jsonData, new(jsonTestData))
+		if err == nil {
+			t.Fatalf("expected error for %s", jsonData)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("error for %s is %q; expected it to contain %q", jsonData, err.Error(), expected)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package solve
+
+import (
+	"fmt"
+	"math"
+)
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// Derivatives of the unknowns of a solved system with respect to its
+// parameters, from the implicit function theorem: if F(u, p) = 0 defines
+// u(p), then du/dp = -(dF/du)^{-1} dF/dp.
+//
+// jacobian is the Jacobian of the full system at the solution, with one row
+// per function and one column per variable (as returned by DiffSystem.Df).
+// rows selects the functions which define the unknowns, unknowns gives
+// their column indices (len(rows) == len(unknowns)) and params gives the
+// columns of the parameters. Variables not listed in unknowns or params are
+// held fixed. Returns d[j][i] = d(unknown i)/d(param j).
+func ImplicitDerivatives(jacobian []vec.Vector, rows, unknowns, params []int) ([]vec.Vector, error) {
+	if len(rows) != len(unknowns) {
+		return nil, fmt.Errorf("ImplicitDerivatives: %d functions for %d unknowns", len(rows), len(unknowns))
+	}
+	Ju := make([]vec.Vector, len(rows))
+	for m, r := range rows {
+		Ju[m] = vec.ZeroVector(len(unknowns))
+		for i, c := range unknowns {
+			Ju[m][i] = jacobian[r][c]
+		}
+	}
+	d := make([]vec.Vector, len(params))
+	for j, c := range params {
+		rhs := vec.ZeroVector(len(rows))
+		for m, r := range rows {
+			rhs[m] = -jacobian[r][c]
+		}
+		du, err := LinearSolve(Ju, rhs)
+		if err != nil {
+			return nil, err
+		}
+		d[j] = du
+	}
+	return d, nil
+}
+
+// Solve A x = b by Gaussian elimination with partial pivoting. A is given by
+// rows and is not modified. Returns an error if A is singular.
+func LinearSolve(A []vec.Vector, b vec.Vector) (vec.Vector, error) {
+	N := len(b)
+	if len(A) != N {
+		return nil, fmt.Errorf("LinearSolve: %d rows for %d right-hand sides", len(A), N)
+	}
+	// augmented matrix [A | b]
+	M := make([]vec.Vector, N)
+	scale := 0.0
+	for i := 0; i < N; i++ {
+		if len(A[i]) != N {
+			return nil, fmt.Errorf("LinearSolve: row %d has length %d; expected %d", i, len(A[i]), N)
+		}
+		M[i] = vec.ZeroVector(N + 1)
+		copy(M[i], A[i])
+		M[i][N] = b[i]
+		for _, a := range A[i] {
+			scale = math.Max(scale, math.Abs(a))
+		}
+	}
+	for col := 0; col < N; col++ {
+		pivot := col
+		for i := col + 1; i < N; i++ {
+			if math.Abs(M[i][col]) > math.Abs(M[pivot][col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(M[pivot][col]) <= 1e-14*scale {
+			return nil, fmt.Errorf("LinearSolve: matrix is singular")
+		}
+		M[col], M[pivot] = M[pivot], M[col]
+		for i := col + 1; i < N; i++ {
+			factor := M[i][col] / M[col][col]
+			for j := col; j <= N; j++ {
+				M[i][j] -= factor * M[col][j]
+			}
+		}
+	}
+	x := vec.ZeroVector(N)
+	for i := N - 1; i >= 0; i-- {
+		sum := M[i][N]
+		for j := i + 1; j < N; j++ {
+			sum -= M[i][j] * x[j]
+		}
+		x[i] = sum / M[i][i]
+	}
+	return x, nil
+}
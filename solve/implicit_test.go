@@ -0,0 +1,32 @@
+package solve
+
+import (
+	"math"
+	"testing"
+)
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// For F_1 = u_1 + u_2 - p, F_2 = u_1 - u_2 - 2p, with an unused variable
+// and function, du/dp = (3/2, -1/2).
+func TestImplicitDerivativesLinear(t *testing.T) {
+	// columns: u_2, unused, u_1, p
+	jacobian := []vec.Vector{{1.0, 0.0, 1.0, -1.0}, {5.0, 1.0, 2.0, 3.0}, {-1.0, 0.0, 1.0, -2.0}}
+	d, err := ImplicitDerivatives(jacobian, []int{0, 2}, []int{2, 0}, []int{3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []float64{1.5, -0.5}
+	for i := range expected {
+		if math.Abs(d[0][i]-expected[i]) > 1e-14 {
+			t.Fatalf("incorrect derivatives %v; expected %v", d[0], expected)
+		}
+	}
+}
+
+func TestLinearSolveSingular(t *testing.T) {
+	A := []vec.Vector{{1.0, 2.0}, {2.0, 4.0}}
+	_, err := LinearSolve(A, vec.Vector{1.0, 2.0})
+	if err == nil {
+		t.Fatal("expected error for singular matrix")
+	}
+}
@@ -26,10 +26,10 @@ func TestIterativeRosenbrock(t *testing.T) {
 }
 
 func rosenbrockSystems(a, b float64, start []vec.Vector) ([]DiffSystem, func([]vec.Vector)) {
-	var x1, x2 float64
+	// only the second stage depends on the state of another stage
+	var x1 float64
 	accept := func(x []vec.Vector) {
 		x1 = x[0][0]
-		x2 = x[1][0]
 	}
 	accept(start)
 	f1 := func(v vec.Vector) (float64, error) {
@@ -0,0 +1,106 @@
+// Single-particle spectral function and simulated ARPES intensity.
+//
+// The holon spectral function in the superconducting state is
+// A(k, omega) = u_k^2 L(omega - E_k) + v_k^2 L(omega + E_k), with
+// u_k^2 = (1 + xi_k/E_k)/2, v_k^2 = 1 - u_k^2 and L a broadened delta
+// function. Frequencies are measured from the holon chemical potential. With
+// F0 = 0 this is the normal-state spectral function L(omega - xi_k).
+package spectral
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Spectral function A(k, omega), with kernel the broadened delta function.
+func A(env *tempAll.Environment, k vec.Vector, omega float64, kernel func(x float64) float64) float64 {
+	E := env.BogoEnergy(k)
+	u2 := env.CoherenceU2(k)
+	return u2*kernel(omega-E) + (1.0-u2)*kernel(omega+E)
+}
+
+// ARPES intensity A(k, omega) f(omega), where f is the Fermi function; only
+// occupied states are seen in photoemission.
+func ARPES(env *tempAll.Environment, k vec.Vector, omega float64, kernel func(x float64) float64) float64 {
+	return A(env, k, omega, kernel) * env.Fermi(omega)
+}
+
+// Evaluate A(k, omega) (or the ARPES intensity, if arpes is true) for each k
+// in ks (for example, the points of a bzone.SymmetryPath) and each omega in
+// omegas. The result is indexed as [omega][k], ready to be passed to PlotMap.
+func Map(env *tempAll.Environment, ks []vec.Vector, omegas []float64, b dos.Broadening, arpes bool) ([][]float64, error) {
+	kernel, err := positiveKernel(b)
+	if err != nil {
+		return nil, err
+	}
+	fn := A
+	if arpes {
+		fn = ARPES
+	}
+	result := make([][]float64, len(omegas))
+	for j, omega := range omegas {
+		result[j] = make([]float64, len(ks))
+		for i, k := range ks {
+			result[j][i] = fn(env, k, omega, kernel)
+		}
+	}
+	return result, nil
+}
+
+// Evaluate A(k, omega) (or the ARPES intensity, if arpes is true) at fixed
+// omega on an L by L grid of (kx, ky) covering the first Brillouin zone, with
+// kz = 0 if the holon dispersion depends on kz. Returns the grid coordinates
+// and the values indexed as [ky][kx].
+func EnergyCut(env *tempAll.Environment, omega float64, L int, b dos.Broadening, arpes bool) ([]float64, [][]float64, error) {
+	if L < 2 {
+		return nil, nil, fmt.Errorf("EnergyCut: need at least 2 points per side; got %d", L)
+	}
+	kernel, err := positiveKernel(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	fn := A
+	if arpes {
+		fn = ARPES
+	}
+	kvals := make([]float64, L)
+	for i := 0; i < L; i++ {
+		kvals[i] = -math.Pi + 2.0*math.Pi*float64(i)/float64(L-1)
+	}
+	k := vec.ZeroVector(env.HolonDim())
+	result := make([][]float64, L)
+	for j, ky := range kvals {
+		result[j] = make([]float64, L)
+		for i, kx := range kvals {
+			k[0], k[1] = kx, ky
+			result[j][i] = fn(env, k, omega, kernel)
+		}
+	}
+	return kvals, result, nil
+}
+
+// Plot the output of Map or EnergyCut as a heatmap with x values xs and y
+// values ys.
+func PlotMap(xs, ys []float64, zs [][]float64, xlabel, ylabel, outPrefix, grapherPath string) error {
+	params := map[string]string{
+		plots.FILE_KEY:   outPrefix,
+		plots.XLABEL_KEY: xlabel,
+		plots.YLABEL_KEY: ylabel,
+		plots.ZLABEL_KEY: "$A(k, \\omega)$",
+	}
+	return plots.PlotHeatmap(xs, ys, zs, params, grapherPath)
+}
+
+// The spectral function needs a finite broadening.
+func positiveKernel(b dos.Broadening) (func(x float64) float64, error) {
+	if b.Width <= 0.0 {
+		return nil, fmt.Errorf("spectral function requires positive broadening width; got %v", b.Width)
+	}
+	return b.Kernel()
+}
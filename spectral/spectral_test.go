@@ -0,0 +1,35 @@
+package spectral
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// A(k, omega) should integrate to 1 over omega for each k.
+func TestSumRule(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 16, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 10.0, "Mu_h": 0.5, "F0": 0.1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks := []vec.Vector{{0.0, 0.0}, {0.5, -1.0}, {math.Pi / 2.0, -math.Pi / 2.0}}
+	omegas := dos.Omegas(-30.0, 30.0, 60001)
+	A, err := Map(env, ks, omegas, dos.Broadening{Kind: "gaussian", Width: 0.05}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := omegas[1] - omegas[0]
+	for i := range ks {
+		total := 0.0
+		for j := range omegas {
+			total += A[j][i] * step
+		}
+		if math.Abs(total-1.0) > 1e-6 {
+			t.Fatalf("A(k, omega) integrates to %v at k = %v", total, ks[i])
+		}
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"io/ioutil"
 	"os"
 )
-import "github.com/tflovorn/scExplorer/serialize"
 
 func LoadEnvCache(cachePath string) ([]interface{}, []error, error) {
 	jsonData, err := ioutil.ReadFile(cachePath)
@@ -18,12 +17,22 @@ func LoadEnvCache(cachePath string) ([]interface{}, []error, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	// caches written by older versions may need to be upgraded
+	_, err = MigrateEnvCacheJSON(cache)
+	if err != nil {
+		return nil, nil, err
+	}
 	ifData := cache["data"].([]interface{})
 	data := make([]interface{}, len(ifData))
 	for i, d := range ifData {
-		env := new(Environment)
-		md := d.(map[string]interface{})
-		serialize.CopyValues(&md, env)
+		if d == nil {
+			// no solution was found for this Environment
+			continue
+		}
+		env, err := newEnvironmentFromJSON(d.(map[string]interface{}))
+		if err != nil {
+			return nil, nil, err
+		}
 		data[i] = *env
 	}
 	ifErrs := cache["errs"].([]interface{})
@@ -0,0 +1,83 @@
+package tempAll
+
+import (
+	"fmt"
+	"math"
+)
+import vec "github.com/tflovorn/scExplorer/vector"
+
+// Single-holon energy without fixed minimum for some hopping model. k has
+// env.HolonDim() components.
+type DispersionModel func(env *Environment, k vec.Vector) float64
+
+// Dispersion models selectable by Environment.Dispersion.
+var dispersionModels = map[string]DispersionModel{
+	"":         epsilonBarSquare,
+	"extended": epsilonBarExtended,
+}
+
+// Add a dispersion model selectable by setting Environment.Dispersion to
+// name. Panics if a model with that name already exists.
+func RegisterDispersion(name string, model DispersionModel) {
+	if _, ok := dispersionModels[name]; ok {
+		panic(fmt.Sprintf("dispersion model %q already registered", name))
+	}
+	dispersionModels[name] = model
+}
+
+// Check that env.Dispersion names a known model and that parameters of the
+// extended model are not given to other models.
+func (env *Environment) validateDispersion() error {
+	if _, ok := dispersionModels[env.Dispersion]; !ok {
+		return fmt.Errorf("Environment.Dispersion = %q is not a known dispersion model", env.Dispersion)
+	}
+	if env.Dispersion != "extended" && (env.T0x != 0.0 || env.T0y != 0.0 || env.Tpp != 0.0 || env.HolonTz != 0.0) {
+		return fmt.Errorf("Environment.T0x, T0y, Tpp and HolonTz require Dispersion = \"extended\"")
+	}
+	return nil
+}
+
+// Number of components of holon momenta: 3 if the holon dispersion depends
+// on kz, 2 otherwise.
+func (env *Environment) HolonDim() int {
+	if env.HolonTz != 0.0 {
+		return 3
+	}
+	return 2
+}
+
+// Square lattice with equal x and y hopping.
+func epsilonBarSquare(env *Environment, k vec.Vector) float64 {
+	sx, sy := math.Sin(k[0]), math.Sin(k[1])
+	return 2.0*env.Th()*((sx+sy)*(sx+sy)-1.0) + 4.0*(2.0*env.D1*env.T0-env.Thp)*sx*sy
+}
+
+// Orthorhombic lattice with nearest-neighbor hopping T0x and T0y (each T0 if
+// not given), third-neighbor hopping Tpp, and interlayer holon hopping
+// HolonTz. Reduces to epsilonBarSquare if T0x = T0y = T0 and Tpp = HolonTz = 0.
+//
+// In the sin(k) convention used here the lattice momentum is p = k + pi/2,
+// so the direct nnn hopping -4 t' cos(px) cos(py) is the -4 Thp sx sy term.
+// Holons hop within one sublattice: the nn term, quadratic in
+// sin(kx) + sin(ky), connects a site to its nnn (1, 1) and (2, 0) neighbors.
+// On that sublattice the (1, 1) sites are first neighbors and (2, 0) second,
+// so the third neighbors are at (2, 2), giving
+// -2 Tpp [cos(2px + 2py) + cos(2px - 2py)] = -4 Tpp cos(2kx) cos(2ky).
+func epsilonBarExtended(env *Environment, k vec.Vector) float64 {
+	Thx, Thy := env.T0*(1.0-env.X), env.T0*(1.0-env.X)
+	if env.T0x != 0.0 {
+		Thx = env.T0x * (1.0 - env.X)
+	}
+	if env.T0y != 0.0 {
+		Thy = env.T0y * (1.0 - env.X)
+	}
+	sx, sy := math.Sin(k[0]), math.Sin(k[1])
+	nn := 2.0*(Thx*sx*sx+Thy*sy*sy+(Thx+Thy)*sx*sy) - (Thx + Thy)
+	nnn := 4.0 * (2.0*env.D1*env.T0 - env.Thp) * sx * sy
+	third := -4.0 * env.Tpp * (1.0 - 2.0*sx*sx) * (1.0 - 2.0*sy*sy)
+	eps := nn + nnn + third
+	if env.HolonTz != 0.0 {
+		eps -= 2.0 * env.HolonTz * (1.0 - env.X) * math.Cos(k[2])
+	}
+	return eps
+}
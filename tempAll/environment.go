@@ -1,6 +1,7 @@
 package tempAll
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
@@ -13,17 +14,35 @@ import (
 
 // Container for variables relevant at all temperatures.
 type Environment struct {
+	// Version of the JSON schema this Environment was serialized with;
+	// older documents are upgraded by MigrateEnvironmentJSON.
+	SchemaVersion int
+
 	// Program parameters:
-	PointsPerSide int // length of one side of the lattice
+	PointsPerSide int `serialize:"required"` // length of one side of the lattice
 
 	// Constant physical parameters:
-	X        float64 // average density of holons
-	T0       float64 // nn one-holon hopping energy
-	Thp      float64 // direct nnn one-holon hopping energy
-	Tz       float64 // inter-planar one-holon hopping energy
-	Alpha    int     // SC gap symmetry parameter (s-wave = +1, d-wave = -1)
+	X        float64 `serialize:"required"` // average density of holons
+	T0       float64 `serialize:"required"` // nn one-holon hopping energy
+	Thp      float64 `serialize:"required"` // direct nnn one-holon hopping energy
+	Tz       float64 `serialize:"required"` // inter-planar one-holon hopping energy
+	Alpha    int     `serialize:"required"` // SC gap symmetry parameter (s-wave = +1, d-wave = -1)
 	Be_field float64 // magnetic field (flux density) times e along the c axis (eB is unitless)
 
+	// Gap form factor: one component, or an s and a d component (s+d, d+is)
+	// with relative amplitude F1 (optional; overrides Alpha if given):
+	Gap []GapComponent
+
+	// Holon dispersion model (optional; see DispersionModel):
+	Dispersion string  // "" for the square lattice, or "extended"
+	T0x, T0y   float64 // extended: nn hopping along x and y (T0 if 0)
+	Tpp        float64 // extended: third-neighbor hopping t''
+	HolonTz    float64 // extended: interlayer holon hopping; if nonzero, holon sums are over kz too
+
+	// Physical energy scale (optional; used only to convert outputs):
+	PhysicalT0     float64 // value of T0 in the units given by PhysicalT0Unit
+	PhysicalT0Unit string  // "meV" or "K"; empty if no physical scale is given
+
 	// Dynamically determined physical parameters:
 	D1   float64 // nnn hopping parameter generated by two-holon hopping
 	Mu_h float64 // holon chemical potential
@@ -31,8 +50,10 @@ type Environment struct {
 	// May be constant or dynamically determined:
 	Beta float64 // inverse temperature
 	F0   float64 // superconducting order parameter (0 if T >= Tc)
+	F1   float64 // amplitude of Gap[1] relative to Gap[0]; solved for by tempZero and tempPair, fixed elsewhere
 	Mu_b float64 // holon pair (bosonic) chemical potential (0 if T <= Tc)
 	A, B float64 // pair spectrum parameters
+	Ay   float64 // pair spectrum y coefficient if different from A (0 if equal to A)
 
 	// Data for plotting
 	Temp float64 // 1/Beta -- to only be used for plotting; may otherwise be invalid
@@ -42,16 +63,29 @@ type Environment struct {
 	// Iterate solution for Mu_b in tempFluc.SolveD1Mu_hMu_b.
 	IterateD1Mu_hMu_b bool
 	// Use kz^2 in pair spectrum - incompatible with finite magnetic field.
-	// If false, use cosine spectrum in tempCrit/tempFluc; cosine spectrum not implemented in tempLow.
+	// If false, use cosine spectrum in tempCrit/tempFluc/tempLow.
 	PairKzSquaredSpectrum bool
 	// If true, include poles giving omega_- spectrum in the calculation.
-	// Has no effect if PairKzSquaredSpectrum = false (TODO - extend to cos(kz) spectrum).
+	// Applies to both the kz^2 and cos(kz) spectra, and at finite field;
+	// see tempCrit.OmegaMinusDiagnostic.
 	// Expect negligible different between this being on or off.
 	OmegaMinusPoles bool
 	// Fix pair spectrum coefficients to their values at Tc (helps match F0 = 0 to Tc in T < Tc calculation).
 	FixedPairCoeffs bool
 	// If FixedPairCoeffs = true, stop varying pair spectrum coefficients after PairCoeffsReady is set to true.
 	PairCoeffsReady bool
+	// Below T_c, use the T >= T_c pair spectrum instead of the Bogoliubov
+	// spectrum when the coefficients are not fixed (the behavior before
+	// schema version 3; see tempLow.X2).
+	NoBogoliubovPairs bool
+	// Tabulate omega_+(q) over the whole zone instead of using the small-q
+	// quadratic fit once the pair spectrum is fixed (zero field only;
+	// requires FixedPairCoeffs; see tempCrit.FixPairSpectrum). The table is
+	// not stored in the Environment.
+	NumericPairSpectrum bool
+	// Points per side of the omega_+ grid if NumericPairSpectrum is set
+	// (DEFAULT_PAIR_SPECTRUM_POINTS if 0).
+	PairSpectrumPoints int
 
 	// Cached values:
 	epsilonMinCache  float64
@@ -69,11 +103,32 @@ func WrapFunc(env *Environment, fn Wrappable) bzone.BzFunc {
 	}
 }
 
-// Create an Environment from the given serialized data.
+// Create an Environment from the given serialized data, migrating it from
+// an older schema version if necessary. Returns an error if jsonData
+// contains unknown keys or values of the wrong type, is missing a required
+// parameter, or describes an invalid Environment.
 func NewEnvironment(jsonData string) (*Environment, error) {
+	doc := make(map[string]interface{})
+	err := json.Unmarshal([]byte(jsonData), &doc)
+	if err != nil {
+		return nil, err
+	}
+	return newEnvironmentFromJSON(doc)
+}
+
+// Create an Environment from the given JSON object.
+func newEnvironmentFromJSON(doc map[string]interface{}) (*Environment, error) {
+	_, err := MigrateEnvironmentJSON(doc)
+	if err != nil {
+		return nil, err
+	}
 	// initialize env with input data
 	env := new(Environment)
-	err := serialize.CopyFromJSON(jsonData, env)
+	err = serialize.CopyValuesStrict(&doc, env)
+	if err != nil {
+		return nil, err
+	}
+	err = env.Validate()
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +143,45 @@ func NewEnvironment(jsonData string) (*Environment, error) {
 	return env, nil
 }
 
+// Check that the parameters of env are physically sensible and compatible
+// with each other.
+func (env *Environment) Validate() error {
+	if env.PointsPerSide <= 0 {
+		return fmt.Errorf("Environment.PointsPerSide = %d must be positive", env.PointsPerSide)
+	}
+	if env.X <= 0.0 || env.X >= 1.0 {
+		return fmt.Errorf("Environment.X = %v must satisfy 0 < X < 1", env.X)
+	}
+	if env.Alpha != 1 && env.Alpha != -1 {
+		return fmt.Errorf("Environment.Alpha = %d must be +1 (s-wave) or -1 (d-wave)", env.Alpha)
+	}
+	if env.PhysicalT0Unit != "" && env.PhysicalT0Unit != "meV" && env.PhysicalT0Unit != "K" {
+		return fmt.Errorf("Environment.PhysicalT0Unit = %q must be \"meV\" or \"K\"", env.PhysicalT0Unit)
+	}
+	if env.PhysicalT0Unit != "" && env.PhysicalT0 <= 0.0 {
+		return fmt.Errorf("Environment.PhysicalT0 = %v must be positive", env.PhysicalT0)
+	}
+	if env.PairKzSquaredSpectrum && env.Be_field != 0.0 {
+		return fmt.Errorf("Environment.PairKzSquaredSpectrum is incompatible with nonzero Be_field (%v)", env.Be_field)
+	}
+	if err := env.validateGap(); err != nil {
+		return err
+	}
+	if err := env.validateDispersion(); err != nil {
+		return err
+	}
+	if env.NumericPairSpectrum && env.Be_field != 0.0 {
+		return fmt.Errorf("Environment.NumericPairSpectrum is incompatible with nonzero Be_field (%v)", env.Be_field)
+	}
+	if env.NumericPairSpectrum && !env.FixedPairCoeffs {
+		return fmt.Errorf("Environment.NumericPairSpectrum requires FixedPairCoeffs")
+	}
+	if env.PairSpectrumPoints < 0 {
+		return fmt.Errorf("Environment.PairSpectrumPoints = %d must not be negative", env.PairSpectrumPoints)
+	}
+	return nil
+}
+
 // Convert to string by marshalling to JSON
 func (env *Environment) String() string {
 	if env.Beta == math.Inf(1) {
@@ -184,10 +278,10 @@ func (env *Environment) Epsilon_h(k vec.Vector) float64 {
 	return env.epsilonBar(k) - env.getEpsilonMin()
 }
 
-// Single-holon energy without fixed minimum.
+// Single-holon energy without fixed minimum, using the model selected by
+// env.Dispersion.
 func (env *Environment) epsilonBar(k vec.Vector) float64 {
-	sx, sy := math.Sin(k[0]), math.Sin(k[1])
-	return 2.0*env.Th()*((sx+sy)*(sx+sy)-1.0) + 4.0*(2.0*env.D1*env.T0-env.Thp)*sx*sy
+	return dispersionModels[env.Dispersion](env, k)
 }
 
 // Get minimum value of env.Epsilon. If env.D1 hasn't changed since the last
@@ -205,7 +299,7 @@ func (env *Environment) setEpsilonMinCache() {
 	worker := func(k vec.Vector) float64 {
 		return env.epsilonBar(k)
 	}
-	env.epsilonMinCache = bzone.Min(env.PointsPerSide, 2, worker)
+	env.epsilonMinCache = bzone.Min(env.PointsPerSide, env.HolonDim(), worker)
 	//println(env.epsilonMinCache)
 }
 
@@ -214,16 +308,30 @@ func (env *Environment) Xi_h(k []float64) float64 {
 	return env.Epsilon_h(k) - env.Mu_h
 }
 
-// Superconducting gap function.
-func (env *Environment) Delta_h(k vec.Vector) float64 {
-	return 4.0 * (env.T0 + env.Tz) * env.F0 * (math.Sin(k[0]) + float64(env.Alpha)*math.Sin(k[1]))
-}
-
 // Bogolyubov quasiparticle energy.
 func (env *Environment) BogoEnergy(k vec.Vector) float64 {
 	xi := env.Xi_h(k)
-	delta := env.Delta_h(k)
-	return math.Sqrt(xi*xi + delta*delta)
+	return math.Sqrt(xi*xi + env.DeltaAbs2_h(k))
+}
+
+// Bogolyubov coherence factor u_k^2 = (1 + xi_k/E_k)/2, taking u_k^2 = 1/2
+// at E_k = 0; v_k^2 = 1 - u_k^2.
+func (env *Environment) CoherenceU2(k vec.Vector) float64 {
+	E := env.BogoEnergy(k)
+	if E == 0.0 {
+		return 0.5
+	}
+	return (1.0 + env.Xi_h(k)/E) / 2.0
+}
+
+// Holon occupation n_k = 1 - (xi_k/E_k) tanh(beta E_k/2), summed over spin;
+// n_k = 1 at E_k = 0. Equals 2 f(xi_k) when F0 = 0.
+func (env *Environment) HolonOccupation(k vec.Vector) float64 {
+	E := env.BogoEnergy(k)
+	if E == 0.0 {
+		return 1.0
+	}
+	return 1.0 - env.Xi_h(k)/E*math.Tanh(env.Beta*E/2.0)
 }
 
 // Fermi distribution function.
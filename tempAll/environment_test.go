@@ -2,6 +2,8 @@ package tempAll
 
 import (
 	"io/ioutil"
+	"math"
+	"math/cmplx"
 	"testing"
 )
 import (
@@ -65,3 +67,190 @@ func TestEnvSet(t *testing.T) {
 		t.Fatalf("env.Set failed to correctly set variable")
 	}
 }
+
+// NewEnvironment should reject unknown keys, mistyped values and invalid
+// parameter combinations.
+func TestNewEnvironmentInvalid(t *testing.T) {
+	base := `"PointsPerSide": 16, "T0": 1.0, "Thp": 0.1, "Tz": 0.1`
+	cases := []string{
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Be_Field": 0.1}`,
+		`{` + base + `, "X": 0.1, "Alpha": "d"}`,
+		`{` + base + `, "Alpha": -1}`,
+		`{` + base + `, "X": 1.5, "Alpha": -1}`,
+		`{` + base + `, "X": 0.1, "Alpha": 0}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "PairKzSquaredSpectrum": true, "Be_field": 0.1}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Dispersion": "hexagonal"}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Tpp": 0.05}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Gap": [{"Basis": "p", "Re": 1.0}]}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Gap": [{"Basis": "s", "Re": 1.0}, {"Basis": "s-ext", "Re": 1.0}]}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Gap": [{"Basis": "d", "Re": 1.0}, {"Basis": "s"}]}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Gap": [{"Basis": "d", "Re": 1.0}], "F1": 0.5}`,
+		`{` + base + `, "X": 0.1, "Alpha": -1, "Gap": [{"Basis": "d", "Re": 1.0}, {"Basis": "s", "Re": 1.0}, {"Basis": "s-ext", "Re": 1.0}]}`,
+	}
+	for _, jsonData := range cases {
+		_, err := NewEnvironment(jsonData)
+		if err == nil {
+			t.Fatalf("expected error from NewEnvironment for %s", jsonData)
+		}
+	}
+}
+
+// The extended dispersion with default parameters should match the square
+// lattice dispersion.
+func TestExtendedDispersionDefault(t *testing.T) {
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	extEnv := env.Copy()
+	extEnv.Dispersion = "extended"
+	ks := []vec.Vector{{0.1, 0.2}, {-1.0, 2.5}, {3.0, -0.7}}
+	for _, k := range ks {
+		sq, ext := env.epsilonBar(k), extEnv.epsilonBar(k)
+		if math.Abs(sq-ext) > 1e-12 {
+			t.Fatalf("extended dispersion %v differs from square dispersion %v at k = %v", ext, sq, k)
+		}
+	}
+}
+
+// Each term of the extended dispersion should match its value computed by
+// hand at k = (0.3, 0.7, 0.4) with X = 0.1, T0 = 1, Thp = 0.1, D1 = 0.1.
+func TestExtendedDispersionTerms(t *testing.T) {
+	env, err := NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.1, "Dispersion": "extended"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := vec.Vector{0.3, 0.7}
+	kz := vec.Vector{0.3, 0.7, 0.4}
+	// Orthorhombic: 2(Thx sx^2 + Thy sy^2 + (Thx + Thy) sx sy) - (Thx + Thy)
+	// + 4(2 D1 T0 - Thp) sx sy with Thx = 0.9*1.2, Thy = 0.9*0.8.
+	orthoEnv := env.Copy()
+	orthoEnv.T0x, orthoEnv.T0y = 1.2, 0.8
+	// Third neighbor: -4 Tpp cos(2kx) cos(2ky) with Tpp = 0.05.
+	tppEnv := env.Copy()
+	tppEnv.Tpp = 0.05
+	// Interlayer: -2 HolonTz (1 - X) cos(kz) with HolonTz = 0.1.
+	tzEnv := env.Copy()
+	tzEnv.HolonTz = 0.1
+	cases := []struct {
+		name     string
+		value    float64
+		expected float64
+	}{
+		{"T0x = 1.2, T0y = 0.8", orthoEnv.epsilonBar(k), -0.2522214307211358},
+		{"Tpp term", tppEnv.epsilonBar(k) - env.epsilonBar(k), -0.028055987280002324},
+		{"HolonTz term", tzEnv.epsilonBar(kz) - env.epsilonBar(k), -0.16579097892051933},
+	}
+	for _, c := range cases {
+		if math.Abs(c.value-c.expected) > 1e-12 {
+			t.Fatalf("%s: got %v; expected %v", c.name, c.value, c.expected)
+		}
+	}
+	if tzEnv.HolonDim() != 3 {
+		t.Fatalf("nonzero HolonTz should make holon sums three-dimensional")
+	}
+}
+
+// A pure d-wave Gap should reproduce Alpha = -1, including when it is given
+// with a global phase.
+func TestGapForm(t *testing.T) {
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.F0 = 0.1
+	dEnv := env.Copy()
+	dEnv.Gap = []GapComponent{{Basis: "d", Re: 1.0}}
+	idEnv := env.Copy()
+	idEnv.Gap = []GapComponent{{Basis: "d", Im: 1.0}}
+	ks := []vec.Vector{{0.1, 0.2}, {-1.0, 2.5}, {3.0, -0.7}}
+	for _, k := range ks {
+		expected := env.DeltaComplex_h(k)
+		if cmplx.Abs(dEnv.DeltaComplex_h(k)-expected) > 1e-12 {
+			t.Fatalf("d-wave Gap %v differs from Alpha = -1 gap %v at k = %v", dEnv.DeltaComplex_h(k), expected, k)
+		}
+		if math.Abs(idEnv.DeltaAbs2_h(k)-env.DeltaAbs2_h(k)) > 1e-12 {
+			t.Fatalf("i*d |Delta|^2 = %v; expected %v at k = %v", idEnv.DeltaAbs2_h(k), env.DeltaAbs2_h(k), k)
+		}
+		if math.Abs(idEnv.Delta_h(k)-env.Delta_h(k)) > 1e-12 || math.Abs(env.Delta_h(k)-real(expected)) > 1e-12 {
+			t.Fatalf("i*d gap without its phase is %v; expected %v at k = %v", idEnv.Delta_h(k), real(expected), k)
+		}
+	}
+}
+
+// The extended-s basis should be minus the s basis, and a d+is gap should
+// have |g|^2 = d^2 + F1^2 s^2.
+func TestGapFormMixed(t *testing.T) {
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.F0 = 0.1
+	sEnv := env.Copy()
+	sEnv.Gap = []GapComponent{{Basis: "s", Re: 1.0}}
+	extEnv := env.Copy()
+	extEnv.Gap = []GapComponent{{Basis: "s-ext", Re: 1.0}}
+	disEnv := env.Copy()
+	disEnv.Gap = []GapComponent{{Basis: "d", Re: 1.0}, {Basis: "s", Im: 1.0}}
+	disEnv.F1 = 0.3
+	ks := []vec.Vector{{0.1, 0.2}, {-1.0, 2.5}, {3.0, -0.7}}
+	for _, k := range ks {
+		s := math.Sin(k[0]) + math.Sin(k[1])
+		d := math.Sin(k[0]) - math.Sin(k[1])
+		if cmplx.Abs(extEnv.GapForm(k)+sEnv.GapForm(k)) > 1e-12 || math.Abs(real(sEnv.GapForm(k))-s) > 1e-12 {
+			t.Fatalf("s-ext form %v is not minus s form %v at k = %v", extEnv.GapForm(k), sEnv.GapForm(k), k)
+		}
+		expected := d*d + disEnv.F1*disEnv.F1*s*s
+		if math.Abs(disEnv.GapWeight(k)-expected) > 1e-12 {
+			t.Fatalf("d+is weight %v; expected %v at k = %v", disEnv.GapWeight(k), expected, k)
+		}
+	}
+}
+
+// With F1 = 0, the first of the coupled gap equations should reduce to the
+// single-channel one, and the second should vanish by tetragonal symmetry.
+func TestGapEquationErrorsDecoupled(t *testing.T) {
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	W := func(k vec.Vector) float64 {
+		return 1.0 / math.Sqrt(math.Pow(env.Xi_h(k), 2.0)+0.01)
+	}
+	single := env.GapEquationErrors(W)
+	for _, second := range []GapComponent{{Basis: "s", Re: 1.0}, {Basis: "s-ext", Im: 1.0}} {
+		mixEnv := env.Copy()
+		mixEnv.Gap = []GapComponent{{Basis: "d", Re: 1.0}, second}
+		mixed := mixEnv.GapEquationErrors(W)
+		if len(single) != 1 || len(mixed) != 2 {
+			t.Fatalf("unexpected number of gap equations: %v, %v", single, mixed)
+		}
+		if math.Abs(mixed[0]-single[0]) > 1e-12 || math.Abs(mixed[1]) > 1e-12 {
+			t.Fatalf("decoupled errors %v; expected [%v 0]", mixed, single[0])
+		}
+	}
+}
+
+// With F0 = 0, the holon occupation should be 2 f(xi) and u_k^2 should be 1
+// on empty states and 0 on occupied ones.
+func TestHolonOccupationNormal(t *testing.T) {
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.F0 = 0.0
+	ks := []vec.Vector{{0.1, 0.2}, {-1.0, 2.5}, {3.0, -0.7}}
+	for _, k := range ks {
+		xi := env.Xi_h(k)
+		if n := env.HolonOccupation(k); math.Abs(n-2.0*env.Fermi(xi)) > 1e-12 {
+			t.Fatalf("occupation %v differs from 2 f(xi) = %v at k = %v", n, 2.0*env.Fermi(xi), k)
+		}
+		expected := 0.0
+		if xi > 0.0 {
+			expected = 1.0
+		}
+		if u2 := env.CoherenceU2(k); u2 != expected {
+			t.Fatalf("u_k^2 = %v at xi = %v; expected %v", u2, xi, expected)
+		}
+	}
+}
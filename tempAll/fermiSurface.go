@@ -1,31 +1,137 @@
 package tempAll
 
-import "strconv"
-import "github.com/tflovorn/scExplorer/plots"
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/contour"
+	"github.com/tflovorn/scExplorer/plots"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
 
 // Make a Fermi surface plot of env, which should be a
 // pre-solved T=0, non-interacting Environment.
-// precise=true makes a better-looking plot (delta->delta/10).
+// precise=true makes a better-looking plot (finer contour grid).
 func FermiSurface(env *Environment, outPrefix, grapherPath string, precise bool) error {
-	// blank data slots - relevant only for scatter plot
-	data := []plots.Series{}
-	seriesParams := []map[string]string{}
-	// required data for FS plot
+	L := 128
+	if precise {
+		L = 1024
+	}
+	lines := FermiContours(env, 0.0, L)
+	data := make([]plots.Series, len(lines))
+	seriesParams := make([]map[string]string, len(lines))
+	for i, line := range lines {
+		// repeat the first point (shifted by the winding) to close the curve
+		N := len(line.Points)
+		xs, ys := make([]float64, N+1), make([]float64, N+1)
+		for n, p := range line.Points {
+			xs[n], ys[n] = p[0], p[1]
+		}
+		xs[N] = line.Points[0][0] + 2.0*math.Pi*float64(line.Winding[0])
+		ys[N] = line.Points[0][1] + 2.0*math.Pi*float64(line.Winding[1])
+		data[i] = plots.MakeSeries(xs, ys)
+		seriesParams[i] = map[string]string{"style": "k-"}
+	}
 	params := make(map[string]string)
-	params["plot_type"] = "Fermi_surface"
 	params[plots.XLABEL_KEY] = "$k_x$"
 	params[plots.YLABEL_KEY] = "$k_y$"
 	params[plots.FILE_KEY] = outPrefix
-	params["th"] = strconv.FormatFloat(env.Th(), 'f', 6, 64)
-	params["thp"] = strconv.FormatFloat(env.Thp, 'f', 6, 64)
-	params["t0"] = strconv.FormatFloat(env.T0, 'f', 6, 64)
-	params["D1"] = strconv.FormatFloat(env.D1, 'f', 6, 64)
-	params["Mu_h"] = strconv.FormatFloat(env.Mu_h, 'f', 6, 64)
-	params["epsilon_min"] = strconv.FormatFloat(env.getEpsilonMin(), 'f', 6, 64)
-	if precise {
-		params["delta"] = strconv.FormatFloat(0.005, 'f', 6, 64)
-	} else {
-		params["delta"] = strconv.FormatFloat(0.05, 'f', 6, 64)
-	}
 	return plots.PlotMPL(data, params, seriesParams, grapherPath)
 }
+
+// Contours Xi_h(k) = omega in the kz = 0 plane, found on an L by L grid.
+// omega = 0 gives the Fermi surface.
+func FermiContours(env *Environment, omega float64, L int) []contour.Polyline {
+	k3 := vec.ZeroVector(3)
+	xi := func(k vec.Vector) float64 {
+		k3[0], k3[1] = k[0], k[1]
+		return env.Xi_h(k3)
+	}
+	return contour.Extract(xi, omega, L)
+}
+
+// Number of each kind of Fermi surface sheet. Electron pockets enclose
+// occupied states (Xi_h < 0); hole pockets enclose empty states; open sheets
+// wrap around the Brillouin zone.
+type FermiTopology struct {
+	Electron, Hole, Open int
+}
+
+func (t FermiTopology) String() string {
+	return fmt.Sprintf("%d electron pocket(s), %d hole pocket(s), %d open sheet(s)", t.Electron, t.Hole, t.Open)
+}
+
+// Topology of the Fermi surface of env, found on an L by L grid.
+func Topology(env *Environment, L int) FermiTopology {
+	t := FermiTopology{}
+	for _, line := range FermiContours(env, 0.0, L) {
+		switch line.Kind() {
+		case contour.ElectronPocket:
+			t.Electron++
+		case contour.HolePocket:
+			t.Hole++
+		case contour.Open:
+			t.Open++
+		}
+	}
+	return t
+}
+
+// Indices i for which the Fermi surface topology of envs[i] differs from that
+// of envs[i-1]. If envs are solutions at increasing X, these bracket the
+// Lifshitz transitions.
+func LifshitzTransitions(envs []*Environment, L int) ([]int, []FermiTopology) {
+	changes := []int{}
+	tops := make([]FermiTopology, len(envs))
+	for i, env := range envs {
+		tops[i] = Topology(env, L)
+		if i > 0 && tops[i] != tops[i-1] {
+			changes = append(changes, i)
+		}
+	}
+	return changes, tops
+}
+
+// Holon velocity grad_k Xi_h in the kx-ky plane, found by central
+// differences so that it applies to any dispersion model.
+func FermiVelocity(env *Environment, k vec.Vector) vec.Vector {
+	h := 1e-5
+	kp := vec.ZeroVector(3)
+	copy(kp, k)
+	v := vec.ZeroVector(2)
+	for d := 0; d < 2; d++ {
+		orig := kp[d]
+		kp[d] = orig + h
+		plus := env.Xi_h(kp)
+		kp[d] = orig - h
+		minus := env.Xi_h(kp)
+		kp[d] = orig
+		v[d] = (plus - minus) / (2.0 * h)
+	}
+	return v
+}
+
+// First and second derivatives of Xi_h with respect to k[dir], by central
+// differences so that they apply to any dispersion model.
+func XiDerivatives(env *Environment, k vec.Vector, dir int) (float64, float64) {
+	h := 1e-4
+	kp := vec.ZeroVector(len(k))
+	copy(kp, k)
+	kp[dir] = k[dir] + h
+	plus := env.Xi_h(kp)
+	kp[dir] = k[dir] - h
+	minus := env.Xi_h(kp)
+	mid := env.Xi_h(k)
+	return (plus - minus) / (2.0 * h), (plus - 2.0*mid + minus) / (h * h)
+}
+
+// Magnitude of the holon velocity at each point of line.
+func ContourVelocities(env *Environment, line contour.Polyline) []float64 {
+	speeds := make([]float64, len(line.Points))
+	for n, p := range line.Points {
+		v := FermiVelocity(env, p)
+		speeds[n] = math.Hypot(v[0], v[1])
+	}
+	return speeds
+}
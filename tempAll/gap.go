@@ -0,0 +1,194 @@
+package tempAll
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// One term in the gap form factor: the basis function named by Basis, with
+// phase given by Re + i*Im (only the phase is used; see Environment.F1).
+type GapComponent struct {
+	Basis  string
+	Re, Im float64
+}
+
+// A basis function for Environment.Gap: sign times the eigenchannel
+// function of the pair propagator named by channel.
+type gapBasis struct {
+	channel string
+	sign    float64
+}
+
+// Basis functions available for Environment.Gap.
+//
+// The pairing interaction and the pair propagator (see tempCrit.lambdaParts)
+// are diagonal in the bond basis sin(kx), sin(ky), so at q = 0 with
+// tetragonal symmetry their eigenchannels are s(k) = sin(kx) + sin(ky) and
+// d(k) = sin(kx) - sin(ky), with equal coupling. In the lattice momentum
+// p = k + pi/2 (see epsilonBarExtended), d(k) = -(cos(px) - cos(py)) is
+// d_{x^2-y^2} and s(k) = -(cos(px) + cos(py)) is extended s-wave: the bond
+// interaction has no on-site s channel. "s-ext" is the extended-s form
+// cos(px) + cos(py) and so lies in the s channel.
+var gapBases = map[string]gapBasis{
+	"s":     {"s", 1.0},
+	"s-ext": {"s", -1.0},
+	"d":     {"d", 1.0},
+}
+
+// Eigenchannel functions of the pairing interaction.
+var gapChannels = map[string]func(k vec.Vector) float64{
+	"s": func(k vec.Vector) float64 { return math.Sin(k[0]) + math.Sin(k[1]) },
+	"d": func(k vec.Vector) float64 { return math.Sin(k[0]) - math.Sin(k[1]) },
+}
+
+// Check that env.Gap has one or two components with known bases and
+// nonzero weights, in different channels, and that F1 is only given for two
+// components.
+func (env *Environment) validateGap() error {
+	if len(env.Gap) > 2 {
+		return fmt.Errorf("Environment.Gap has %d components; at most one per channel (s and d) is supported", len(env.Gap))
+	}
+	for i, c := range env.Gap {
+		b, ok := gapBases[c.Basis]
+		if !ok {
+			return fmt.Errorf("Environment.Gap[%d].Basis = %q must be \"s\", \"s-ext\" or \"d\"", i, c.Basis)
+		}
+		if c.Re == 0.0 && c.Im == 0.0 {
+			return fmt.Errorf("Environment.Gap[%d] has zero weight", i)
+		}
+		if i == 1 && b.channel == gapBases[env.Gap[0].Basis].channel {
+			return fmt.Errorf("Environment.Gap[0] and Gap[1] (%q and %q) are both in the %s channel", env.Gap[0].Basis, c.Basis, b.channel)
+		}
+	}
+	if env.F1 != 0.0 && len(env.Gap) != 2 {
+		return fmt.Errorf("Environment.F1 requires a Gap with two components")
+	}
+	return nil
+}
+
+// True if env.Gap mixes the s and d channels, so that the relative
+// amplitude F1 is determined by the coupled gap equations.
+func (env *Environment) MixedGap() bool {
+	return len(env.Gap) == 2
+}
+
+// Phase u_i of Gap[i].
+func (env *Environment) gapPhase(i int) complex128 {
+	w := complex(env.Gap[i].Re, env.Gap[i].Im)
+	return w / complex(cmplx.Abs(w), 0.0)
+}
+
+// Weight of Gap[i] in the gap form factor: its phase, times F1 for the
+// second component.
+func (env *Environment) gapWeight(i int) complex128 {
+	if i == 1 {
+		return complex(env.F1, 0.0) * env.gapPhase(1)
+	}
+	return env.gapPhase(0)
+}
+
+// Value at k of the basis function of Gap[i].
+func (env *Environment) gapBasisValue(i int, k vec.Vector) float64 {
+	b := gapBases[env.Gap[i].Basis]
+	return b.sign * gapChannels[b.channel](k)
+}
+
+// Gap form factor g(k), with Delta_h(k) = 4*(T0 + Tz)*F0*g(k). If env.Gap is
+// empty, g(k) = sin(kx) + Alpha*sin(ky). Otherwise g(k) = u0*b0(k) +
+// F1*u1*b1(k), where b_i is the basis function and u_i the phase of Gap[i].
+func (env *Environment) GapForm(k vec.Vector) complex128 {
+	if len(env.Gap) == 0 {
+		return complex(math.Sin(k[0])+float64(env.Alpha)*math.Sin(k[1]), 0.0)
+	}
+	g := complex(0.0, 0.0)
+	for i := range env.Gap {
+		g += env.gapWeight(i) * complex(env.gapBasisValue(i, k), 0.0)
+	}
+	return g
+}
+
+// |g(k)|^2; the weight of k in the single-channel gap equations.
+func (env *Environment) GapWeight(k vec.Vector) float64 {
+	g := env.GapForm(k)
+	return real(g)*real(g) + imag(g)*imag(g)
+}
+
+// Global phase of the gap form factor, taken from its first component.
+// For a single channel g(k) = GapPhase() times a real function of k; for a
+// mixed gap, Delta_h(k)*conj(GapPhase()) has the first component real.
+func (env *Environment) GapPhase() complex128 {
+	if len(env.Gap) == 0 {
+		return complex(1.0, 0.0)
+	}
+	return env.gapPhase(0)
+}
+
+// Errors in the gap equations
+//
+//	u_i^* [w_i/(T0 + Tz) - \sum_j <b_i(k) b_j(k) W(k)> w_j] = 0
+//
+// for each component i of the gap form factor, with w_0 = u_0 and
+// w_1 = F1*u_1 (see GapForm), divided through by F0. W(k) is the kernel
+// of the gap equation: 1/E_k at T = 0, tanh(beta*xi_k/2)/xi_k at the
+// pairing temperature. The real part of each is returned; the imaginary
+// parts vanish for real (s+d) mixing, and for d+is mixing when the
+// lattice is tetragonal. For a single channel (including Alpha) this is
+// the usual 1/(T0 + Tz) - <g(k)^2 W(k)>.
+func (env *Environment) GapEquationErrors(W func(k vec.Vector) float64) []float64 {
+	lhs := 1.0 / (env.T0 + env.Tz)
+	L, dim := env.PointsPerSide, env.HolonDim()
+	if !env.MixedGap() {
+		inner := func(k vec.Vector) float64 {
+			return env.GapWeight(k) * W(k)
+		}
+		return []float64{lhs - bzone.Avg(L, dim, inner)}
+	}
+	// M = {<b0 b0 W>, <b0 b1 W>, <b1 b1 W>}
+	inner := func(k vec.Vector, out *vec.Vector) {
+		b0, b1, w := env.gapBasisValue(0, k), env.gapBasisValue(1, k), W(k)
+		(*out)[0] = b0 * b0 * w
+		(*out)[1] = b0 * b1 * w
+		(*out)[2] = b1 * b1 * w
+	}
+	M := bzone.VectorAvg(L, dim, 3, inner)
+	cos01 := real(env.gapPhase(1) * cmplx.Conj(env.gapPhase(0)))
+	err0 := lhs - (M[0] + env.F1*M[1]*cos01)
+	err1 := env.F1*lhs - (M[1]*cos01 + env.F1*M[2])
+	return []float64{err0, err1}
+}
+
+// Superconducting gap function, including its phase.
+func (env *Environment) DeltaComplex_h(k vec.Vector) complex128 {
+	return complex(4.0*(env.T0+env.Tz)*env.F0, 0.0) * env.GapForm(k)
+}
+
+// |Delta_h(k)|^2.
+func (env *Environment) DeltaAbs2_h(k vec.Vector) float64 {
+	amp := 4.0 * (env.T0 + env.Tz) * env.F0
+	return amp * amp * env.GapWeight(k)
+}
+
+// Superconducting gap function with the global phase GapPhase removed.
+//
+// Deprecated: Delta_h is real only for a single-channel gap; for a d+is gap
+// it drops the imaginary component. Use DeltaComplex_h or DeltaAbs2_h.
+func (env *Environment) Delta_h(k vec.Vector) float64 {
+	return real(env.DeltaComplex_h(k) * cmplx.Conj(env.GapPhase()))
+}
+
+// Maximum of |g(k)| over the Brillouin zone; 2 for the default
+// g(k) = sin(kx) +/- sin(ky).
+func (env *Environment) GapFormMax() float64 {
+	if len(env.Gap) == 0 {
+		return 2.0
+	}
+	negAbs := func(k vec.Vector) float64 {
+		return -math.Sqrt(env.GapWeight(k))
+	}
+	return -bzone.Min(env.PointsPerSide, 2, negAbs)
+}
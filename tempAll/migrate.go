@@ -0,0 +1,204 @@
+package tempAll
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Current version of the Environment JSON schema. When a change to
+// Environment would cause older documents to load incorrectly, increment
+// this and register a migration from the previous version in migrations.
+const SCHEMA_VERSION = 3
+
+// Upgrade doc, an Environment JSON object of some schema version v, to
+// version v+1 in place. solved is true if doc holds the output of a solver
+// (an entry of a solution cache) rather than input that will be solved
+// again.
+type Migration func(doc map[string]interface{}, solved bool) error
+
+// migrations[v] upgrades a document from version v to version v+1.
+var migrations = map[int]Migration{
+	0: migrateUnversioned,
+	1: migrateGapWeight,
+	2: migrateBogoliubovPairs,
+}
+
+// Add a migration from schema version `from` to `from`+1. Panics if a
+// migration from that version already exists.
+func RegisterMigration(from int, m Migration) {
+	if _, ok := migrations[from]; ok {
+		panic(fmt.Sprintf("migration from schema version %d already registered", from))
+	}
+	migrations[from] = m
+}
+
+// Documents written before SchemaVersion was introduced have no version
+// field; otherwise their layout matches version 1.
+func migrateUnversioned(doc map[string]interface{}, solved bool) error {
+	return nil
+}
+
+// Before schema version 2, the T > 0 gap equations (tempPair, tempFluc,
+// tempLow) used the d-wave weight (sin kx - sin ky)^2 whatever Alpha was,
+// while Delta_h and tempZero used sin kx + Alpha*sin ky. Documents with
+// Alpha = +1 and no Gap were solved with this inconsistent mixture, which
+// cannot be reproduced; such solutions must be re-solved. Input documents
+// are solved again with consistent weights, and other documents are
+// unchanged.
+func migrateGapWeight(doc map[string]interface{}, solved bool) error {
+	if !solved {
+		return nil
+	}
+	alpha, _ := doc["Alpha"].(float64)
+	if alpha == 1.0 && doc["Gap"] == nil {
+		return fmt.Errorf("Alpha = +1 results used d-wave weights in the T > 0 gap equations and must be re-solved")
+	}
+	return nil
+}
+
+// Since schema version 3, tempLow uses the Bogoliubov pair spectrum with the
+// cos(kz) form below T_c unless the pair spectrum is fixed or has the kz^2
+// form. Solutions in older caches which would be affected were found with
+// the T >= T_c spectrum; keep it for them. Input documents are solved again
+// and get the current behavior.
+func migrateBogoliubovPairs(doc map[string]interface{}, solved bool) error {
+	if !solved {
+		return nil
+	}
+	kzSquared, _ := doc["PairKzSquaredSpectrum"].(bool)
+	fixed, _ := doc["FixedPairCoeffs"].(bool)
+	if !kzSquared && !fixed {
+		doc["NoBogoliubovPairs"] = true
+	}
+	return nil
+}
+
+// Return the schema version recorded in doc, or unversioned if none is
+// recorded.
+func schemaVersion(doc map[string]interface{}, unversioned int) (int, error) {
+	v, ok := doc["SchemaVersion"]
+	if !ok {
+		return unversioned, nil
+	}
+	vf, ok := v.(float64)
+	if !ok || vf != float64(int(vf)) || vf < 0 {
+		return 0, fmt.Errorf("invalid SchemaVersion %v", v)
+	}
+	return int(vf), nil
+}
+
+// Upgrade doc, an Environment JSON object, to SCHEMA_VERSION in place.
+// Returns true if doc was changed. A document with no SchemaVersion is taken
+// to be hand-written input for the current version and is only stamped with
+// it; documents written before versioning was introduced are upgraded by
+// MigrateFile instead.
+func MigrateEnvironmentJSON(doc map[string]interface{}) (bool, error) {
+	return migrateEnvironmentJSON(doc, SCHEMA_VERSION, false)
+}
+
+// Upgrade doc to SCHEMA_VERSION in place, taking a document with no
+// SchemaVersion to be of version unversioned. solved is passed on to each
+// Migration.
+func migrateEnvironmentJSON(doc map[string]interface{}, unversioned int, solved bool) (bool, error) {
+	version, err := schemaVersion(doc, unversioned)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := doc["SchemaVersion"]; !ok && version == SCHEMA_VERSION {
+		doc["SchemaVersion"] = float64(SCHEMA_VERSION)
+		return true, nil
+	}
+	if version > SCHEMA_VERSION {
+		return false, fmt.Errorf("Environment schema version %d is newer than supported version %d", version, SCHEMA_VERSION)
+	}
+	changed := false
+	for ; version < SCHEMA_VERSION; version++ {
+		m, ok := migrations[version]
+		if !ok {
+			return changed, fmt.Errorf("no migration from Environment schema version %d", version)
+		}
+		err = m(doc, solved)
+		if err != nil {
+			return changed, fmt.Errorf("migrating Environment from schema version %d: %v", version, err)
+		}
+		doc["SchemaVersion"] = float64(version + 1)
+		changed = true
+	}
+	return changed, nil
+}
+
+// Upgrade each Environment in cache (as written by SaveEnvCache) to
+// SCHEMA_VERSION in place. Returns true if cache was changed. Caches are
+// always written with a SchemaVersion, so an unversioned cache entry was
+// written before versioning was introduced.
+func MigrateEnvCacheJSON(cache map[string]interface{}) (bool, error) {
+	ifData, ok := cache["data"].([]interface{})
+	if !ok {
+		return false, fmt.Errorf("cache has no data list")
+	}
+	changed := false
+	for i, d := range ifData {
+		if d == nil {
+			// failed solution; nothing to migrate
+			continue
+		}
+		md, ok := d.(map[string]interface{})
+		if !ok {
+			return changed, fmt.Errorf("cache data[%d] is not an Environment", i)
+		}
+		thisChanged, err := migrateEnvironmentJSON(md, 0, true)
+		if err != nil {
+			return changed, fmt.Errorf("cache data[%d]: %v", i, err)
+		}
+		changed = changed || thisChanged
+	}
+	return changed, nil
+}
+
+// Rewrite the Environment input or cache file at path using the current
+// schema version. The kind of file is detected from its contents. Unlike
+// NewEnvironment, an input file with no SchemaVersion is taken to have been
+// written before versioning was introduced. Returns true if the file needed
+// to be changed.
+func MigrateFile(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	jsonData, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	doc := make(map[string]interface{})
+	err = json.Unmarshal(jsonData, &doc)
+	if err != nil {
+		return false, err
+	}
+	var changed bool
+	var marshalled []byte
+	_, hasData := doc["data"]
+	_, hasErrs := doc["errs"]
+	if hasData && hasErrs {
+		changed, err = MigrateEnvCacheJSON(doc)
+		if err != nil || !changed {
+			return false, err
+		}
+		marshalled, err = json.Marshal(doc)
+	} else {
+		changed, err = migrateEnvironmentJSON(doc, 0, false)
+		if err != nil || !changed {
+			return false, err
+		}
+		marshalled, err = json.MarshalIndent(doc, "", "  ")
+	}
+	if err != nil {
+		return false, err
+	}
+	err = ioutil.WriteFile(path, marshalled, info.Mode().Perm())
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
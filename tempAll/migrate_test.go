@@ -0,0 +1,153 @@
+package tempAll
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// Unversioned documents are hand-written input for the current schema
+// version: they should be stamped with it and not migrated.
+func TestMigrateUnversioned(t *testing.T) {
+	data, err := ioutil.ReadFile("environment_test_env.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := make(map[string]interface{})
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc["Alpha"] = 1.0
+	changed, err := MigrateEnvironmentJSON(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed || doc["SchemaVersion"] != float64(SCHEMA_VERSION) || doc["NoBogoliubovPairs"] != nil {
+		t.Fatalf("unversioned document not stamped with the current version: %v", doc)
+	}
+	changed, err = MigrateEnvironmentJSON(doc)
+	if err != nil || changed {
+		t.Fatalf("current document should not change on migration")
+	}
+	env, err := envDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.SchemaVersion != SCHEMA_VERSION {
+		t.Fatalf("NewEnvironment produced schema version %d", env.SchemaVersion)
+	}
+}
+
+// Documents from a newer schema version should be rejected.
+func TestMigrateNewerVersion(t *testing.T) {
+	doc := map[string]interface{}{"SchemaVersion": float64(SCHEMA_VERSION + 1)}
+	_, err := MigrateEnvironmentJSON(doc)
+	if err == nil {
+		t.Fatalf("expected error migrating newer schema version")
+	}
+}
+
+// Alpha = +1 documents from before the gap weight change cannot be migrated.
+func TestMigrateGapWeight(t *testing.T) {
+	cache := map[string]interface{}{
+		"data": []interface{}{map[string]interface{}{"SchemaVersion": 1.0, "Alpha": 1.0}},
+		"errs": []interface{}{""},
+	}
+	_, err := MigrateEnvCacheJSON(cache)
+	if err == nil {
+		t.Fatalf("expected error migrating Alpha = +1 solution")
+	}
+	cache = map[string]interface{}{
+		"data": []interface{}{map[string]interface{}{"SchemaVersion": 1.0, "Alpha": -1.0}},
+		"errs": []interface{}{""},
+	}
+	changed, err := MigrateEnvCacheJSON(cache)
+	doc := cache["data"].([]interface{})[0].(map[string]interface{})
+	if err != nil || !changed || doc["SchemaVersion"] != float64(SCHEMA_VERSION) {
+		t.Fatalf("Alpha = -1 solution not migrated: %v (%v)", doc, err)
+	}
+	// Input documents are solved again, so need no error.
+	doc = map[string]interface{}{"SchemaVersion": 1.0, "Alpha": 1.0}
+	_, err = migrateEnvironmentJSON(doc, 0, false)
+	if err != nil || doc["SchemaVersion"] != float64(SCHEMA_VERSION) {
+		t.Fatalf("Alpha = +1 input document not migrated: %v (%v)", doc, err)
+	}
+}
+
+// Solutions from before the Bogoliubov pair spectrum should keep the
+// T >= T_c spectrum below T_c unless it is fixed. Input documents should be
+// left alone.
+func TestMigrateBogoliubovPairs(t *testing.T) {
+	cache := map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"SchemaVersion": 2.0},
+			map[string]interface{}{"SchemaVersion": 2.0, "FixedPairCoeffs": true},
+		},
+		"errs": []interface{}{"", ""},
+	}
+	_, err := MigrateEnvCacheJSON(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := cache["data"].([]interface{})
+	doc := data[0].(map[string]interface{})
+	if doc["NoBogoliubovPairs"] != true {
+		t.Fatalf("NoBogoliubovPairs not set on migration: %v", doc)
+	}
+	doc = data[1].(map[string]interface{})
+	if doc["NoBogoliubovPairs"] != nil {
+		t.Fatalf("NoBogoliubovPairs set for fixed pair spectrum: %v", doc)
+	}
+	doc = map[string]interface{}{"SchemaVersion": 2.0}
+	_, err = migrateEnvironmentJSON(doc, 0, false)
+	if err != nil || doc["NoBogoliubovPairs"] != nil {
+		t.Fatalf("NoBogoliubovPairs set for input document: %v (%v)", doc, err)
+	}
+}
+
+// MigrateFile should rewrite an old (unversioned) input file in place,
+// keeping its permissions.
+func TestMigrateFile(t *testing.T) {
+	data, err := ioutil.ReadFile("environment_test_env.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	path := wd + "/deleteme.migrate_test"
+	err = ioutil.WriteFile(path, data, 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// WriteFile leaves an existing file's mode alone
+	err = os.Chmod(path, 0640)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := MigrateFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !changed {
+		t.Fatalf("old input file not migrated")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0640 {
+		t.Fatalf("migrated file has mode %v; expected %v", info.Mode().Perm(), os.FileMode(0640))
+	}
+	migrated, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := NewEnvironment(string(migrated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.SchemaVersion != SCHEMA_VERSION || env.X != 0.1 {
+		t.Fatalf("incorrect Environment loaded from migrated file")
+	}
+}
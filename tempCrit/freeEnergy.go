@@ -0,0 +1,144 @@
+package tempCrit
+
+import (
+	"errors"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Calculate Omega_1/N = -T/N \sum_k ln(1 + e^{-\beta \xi_h(k)}), the grand
+// potential of unpaired holons.
+func HolonGrandPotential(env *tempAll.Environment) (float64, error) {
+	inner := func(k vec.Vector) float64 {
+		xi := env.Xi_h(k)
+		// written in terms of |xi| to avoid overflow at low temperature
+		return math.Min(xi, 0.0) - math.Log1p(math.Exp(-env.Beta*math.Abs(xi)))/env.Beta
+	}
+	return bzone.Avg(env.PointsPerSide, env.HolonDim(), inner), nil
+}
+
+// Calculate F_1/N = Omega_1/N + \mu_h x_1, the Helmholtz free energy of
+// unpaired holons.
+func HolonFreeEnergy(env *tempAll.Environment) (float64, error) {
+	omega, err := HolonGrandPotential(env)
+	if err != nil {
+		return 0.0, err
+	}
+	inner := func(k vec.Vector) float64 {
+		return env.Fermi(env.Xi_h(k))
+	}
+	x1 := bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+	return omega + env.Mu_h*x1, nil
+}
+
+// Calculate S_1/N = (U_1 - F_1)/(NT) = -\partial(\Omega_1/N)/\partial T,
+// the entropy of unpaired holons.
+func HolonEntropy(env *tempAll.Environment) (float64, error) {
+	if math.IsInf(env.Beta, 1) {
+		return 0.0, nil
+	}
+	inner := func(k vec.Vector) float64 {
+		return FermionEntropy(env.Beta * math.Abs(env.Xi_h(k)))
+	}
+	return bzone.Avg(env.PointsPerSide, env.HolonDim(), inner), nil
+}
+
+// Calculate Omega_2/N = T/N \sum_k ln(1 - e^{-\beta(\omega_+(k) - \mu_b)}),
+// the grand potential of uncondensed pairs. Only the quadratic-fit cos(kz)
+// spectrum and the tabulated spectrum in zero field are supported.
+func PairGrandPotential(env *tempAll.Environment) (float64, error) {
+	lnTerm := func(x float64) float64 {
+		return math.Log(-math.Expm1(-x)) / env.Beta
+	}
+	return pairThermoIntegral(env, lnTerm)
+}
+
+// Calculate F_2/N = Omega_2/N + \mu_b x_2/2, the Helmholtz free energy of
+// uncondensed pairs (x_2/2 is the pair concentration).
+func PairFreeEnergy(env *tempAll.Environment) (float64, error) {
+	omega, err := PairGrandPotential(env)
+	if err != nil {
+		return 0.0, err
+	}
+	x2, err := X2(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return omega + env.Mu_b*x2/2.0, nil
+}
+
+// Calculate S_2/N = (U_2 - F_2)/(NT) = -\partial(\Omega_2/N)/\partial T, the
+// entropy of uncondensed pairs, with the pair spectrum held fixed.
+func PairEntropy(env *tempAll.Environment) (float64, error) {
+	return pairThermoIntegral(env, BosonEntropy)
+}
+
+// Entropy of a fermion mode with energy x/beta: ln(1 + e^{-x}) + x f(x),
+// written in terms of |x| to avoid overflow.
+func FermionEntropy(x float64) float64 {
+	x = math.Abs(x)
+	if x > 700.0 {
+		return 0.0
+	}
+	return math.Log1p(math.Exp(-x)) + x/(math.Exp(x)+1.0)
+}
+
+// Entropy of a boson mode with energy x/beta > 0:
+// x n_b(x) - ln(1 - e^{-x}).
+func BosonEntropy(x float64) float64 {
+	if x > 700.0 {
+		return 0.0
+	}
+	return x/math.Expm1(x) - math.Log(-math.Expm1(-x))
+}
+
+// 1/N \sum_k F(\beta(\omega(k) - \mu_b)) over the pair spectrum, with the
+// same cases as X2.
+func pairThermoIntegral(env *tempAll.Environment, F func(float64) float64) (float64, error) {
+	if math.Abs(env.Be_field) >= 1e-9 {
+		return 0.0, errors.New("pair free energy is not implemented in finite magnetic field")
+	}
+	if usePairSpectrumGrid(env) {
+		g, err := envOmegaGrid(env)
+		if err != nil {
+			return 0.0, err
+		}
+		return g.Avg(func(omega float64) float64 {
+			return F(env.Beta * g.excitation(env, omega))
+		}), nil
+	}
+	if env.PairKzSquaredSpectrum {
+		return 0.0, errors.New("pair free energy is not implemented for the kz^2 pair spectrum")
+	}
+	if -env.Mu_b > -2.0*env.Mu_h {
+		return 0.0, nil
+	}
+	ax, ay, b, err := PairCoeffs(env)
+	if err != nil {
+		// X2 treats this case as having no pairs
+		return 0.0, nil
+	}
+	integrand := func(b, mu_relative float64) func(float64, float64) float64 {
+		return func(y, kz float64) float64 {
+			bterm := 2.0 * b * (1.0 - math.Cos(kz))
+			return F(y + env.Beta*(bterm-env.Mu_b-mu_relative))
+		}
+	}
+	plus, err := OmegaIntegralCos(env, ax, ay, b, integrand(b, 0.0))
+	if err != nil {
+		return 0.0, err
+	}
+	axM, ayM, bM, muM, minus := omegaMinusCoeffs(env)
+	if minus {
+		minusVal, err := OmegaMinusIntegralCos(env, axM, ayM, bM, muM, integrand(bM, muM))
+		if err != nil {
+			return 0.0, err
+		}
+		return plus + minusVal, nil
+	}
+	return plus, nil
+}
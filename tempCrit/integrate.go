@@ -10,29 +10,32 @@ import (
 )
 
 // Integrate F * n_BE(omega_+) over relevant energy range.
-func OmegaIntegralY(env *tempAll.Environment, a, b float64, F func(float64) float64) (float64, error) {
-	return omegaIntegralYHelper(env, a, b, 0.0, F)
+func OmegaIntegralY(env *tempAll.Environment, ax, ay, b float64, F func(float64) float64) (float64, error) {
+	return omegaIntegralYHelper(env, ax, ay, b, 0.0, F)
 }
 
 // Integrate F * n_BE(omega_-) over relevant energy range.
-func OmegaMinusIntegralY(env *tempAll.Environment, a, b, mu_relative float64, F func(float64) float64) (float64, error) {
-	return omegaIntegralYHelper(env, a, b, mu_relative, F)
+func OmegaMinusIntegralY(env *tempAll.Environment, ax, ay, b, mu_relative float64, F func(float64) float64) (float64, error) {
+	return omegaIntegralYHelper(env, ax, ay, b, mu_relative, F)
 }
 
-// Calculate the integral of y from 0 to ymax of: F(y) / (4*pi^2*a*sqrt(b)).
-// a, b are parameters in pair spectrum: omega_+(k) = a*(kx^2 + ky^2) + b*(kz^2).
+// Calculate the integral of y from 0 to ymax of: F(y) / (4*pi^2*a*sqrt(b)),
+// with a = sqrt(ax*ay).
+// ax, ay, b are parameters in pair spectrum: omega_+(k) = ax*kx^2 + ay*ky^2 + b*(kz^2).
 // mu_relative = 0 for omega_+ poles; = omegaCoeffs[3] for omega_- poles.
-func omegaIntegralYHelper(env *tempAll.Environment, a, b, mu_relative float64, F func(float64) float64) (float64, error) {
-	if a == 0.0 || b == 0.0 {
+func omegaIntegralYHelper(env *tempAll.Environment, ax, ay, b, mu_relative float64, F func(float64) float64) (float64, error) {
+	if ax == 0.0 || ay == 0.0 || b == 0.0 {
 		return 0.0, nil
 	}
+	// the smaller in-plane coefficient determines where the zone edge is reached
+	a, aMin := LandauA(ax, ay), math.Min(ax, ay)
 	ymax := env.Beta * (-2.0*env.Mu_h + env.Mu_b + mu_relative)
 	if ymax <= 0.0 {
 		return 0.0, nil
 	}
-	upper_a := math.Sqrt(ymax / (2.0 * env.Beta * a))
+	upper_a := math.Sqrt(ymax / (2.0 * env.Beta * aMin))
 	if upper_a > math.Pi {
-		ymax = 2.0 * env.Beta * a * math.Pow(math.Pi, 2.0)
+		ymax = 2.0 * env.Beta * aMin * math.Pow(math.Pi, 2.0)
 	}
 	upper_b := math.Sqrt(ymax / (env.Beta * b))
 	if upper_b > math.Pi {
@@ -51,23 +54,37 @@ func omegaIntegralYHelper(env *tempAll.Environment, a, b, mu_relative float64, F
 	return val, err
 }
 
-// Calculate the integral of y from 0 to ymax of: F(y) / (8*pi^2*a).
-// a, b are parameters in pair spectrum: omega_+(k) = a*(kx^2 + ky^2) + 2b*(1 - cos(kz))
-func OmegaIntegralCos(env *tempAll.Environment, a, b float64, F func(float64, float64) float64) (float64, error) {
-	if a == 0.0 || b == 0.0 {
+// Calculate the integral of y from 0 to ymax of: F(y) / (8*pi^2*a), with
+// a = sqrt(ax*ay).
+// ax, ay, b are parameters in pair spectrum: omega_+(k) = ax*kx^2 + ay*ky^2 + 2b*(1 - cos(kz))
+func OmegaIntegralCos(env *tempAll.Environment, ax, ay, b float64, F func(float64, float64) float64) (float64, error) {
+	return omegaIntegralCosHelper(env, ax, ay, b, 0.0, F)
+}
+
+// Integrate F over the omega_- spectrum with the cos(kz) form, as in
+// OmegaIntegralCos.
+func OmegaMinusIntegralCos(env *tempAll.Environment, ax, ay, b, mu_relative float64, F func(float64, float64) float64) (float64, error) {
+	return omegaIntegralCosHelper(env, ax, ay, b, mu_relative, F)
+}
+
+// mu_relative = 0 for omega_+ poles; = omegaCoeffs[3] for omega_- poles.
+func omegaIntegralCosHelper(env *tempAll.Environment, ax, ay, b, mu_relative float64, F func(float64, float64) float64) (float64, error) {
+	if ax == 0.0 || ay == 0.0 || b == 0.0 {
 		return 0.0, nil
 	}
+	// the smaller in-plane coefficient determines where the zone edge is reached
+	a, aMin := LandauA(ax, ay), math.Min(ax, ay)
 	// define the inner integral
 	t := 1e-7
 	integral_inner := func(kz float64) float64 {
 		bterm := 2.0 * b * (1.0 - math.Cos(kz))
-		ymax := env.Beta * (-2.0*env.Mu_h + env.Mu_b - bterm)
+		ymax := env.Beta * (-2.0*env.Mu_h + env.Mu_b + mu_relative - bterm)
 		if ymax <= 0.0 {
 			return 0.0
 		}
-		if ymax/env.Beta > a*math.Pow(math.Pi, 2.0) {
+		if ymax/env.Beta > aMin*math.Pow(math.Pi, 2.0) {
 			fmt.Println("ymax = %f is too large, replacing with (beta*a*pi^2)", ymax)
-			ymax = env.Beta * a * math.Pow(math.Pi, 2.0)
+			ymax = env.Beta * aMin * math.Pow(math.Pi, 2.0)
 		}
 		innerF := func(y float64) float64 {
 			return F(y, kz)
@@ -19,49 +19,24 @@ func Magnetization(env *tempAll.Environment) (float64, error) {
 		return 0.0, nil
 	}
 	// find omega_+ coefficients
-	a, b := env.A, env.B
-	if !env.FixedPairCoeffs || !env.PairCoeffsReady {
-		plusCoeffs, err := OmegaFit(env, OmegaPlus)
-		//fmt.Printf("plusCoeffs in Magnetization: %v\n", plusCoeffs)
-		if err != nil {
-			fmt.Println("suppressing error in magnetization - cannot find pair spectrum")
-			return 0.0, nil
-		}
-		a, b = plusCoeffs[0], plusCoeffs[2]
-	}
-	MSumTerm := func(ri int) float64 {
-		r := float64(ri)
-		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * r)
-		omega_c := 4.0 * env.Be_field * a
-		mu_tilde := env.Mu_b - omega_c/2.0
-		exp := -math.Expm1(-r * env.Beta * omega_c)
-		bracket := 1.0/(env.Beta*r*exp) - omega_c*math.Exp(-r*env.Beta*omega_c)/(exp*exp)
-		return I0 * math.Exp(r*env.Beta*(mu_tilde-2.0*b)) * bracket
-		/*
-		bracket_num_term := func(ni int) float64 {
-			n := float64(ni)
-			nm1_fact := math.Gamma(n)
-			return -math.Pow(-r * env.Beta * omega_c, n + 1.0) / ((n + 1.0) * nm1_fact)
-		}
-		bracket_denom_term := func(ni int) float64 {
-			n := float64(ni)
-			n_fact := math.Gamma(n + 1.0)
-			return math.Pow(-r * env.Beta * omega_c, n) * (math.Pow(2.0, n - 1.0) - 1.0) / n_fact
-		}
-		bracket_num, _ := seriesaccel.Levin_u(bracket_num_term, 1, 20)
-		bracket_denom, _ := seriesaccel.Levin_u(bracket_denom_term, 2, 20)
-		return I0 * math.Exp(r*env.Beta*(mu_tilde-2.0*b)) * bracket_num / (2.0 * bracket_denom)
-		*/
+	ax, ay, b, err := PairCoeffs(env)
+	if err != nil {
+		fmt.Println("suppressing error in magnetization - cannot find pair spectrum")
+		return 0.0, nil
 	}
-	//sum, absErr := seriesaccel.Levin_u(MSumTerm, 1, 20)
-	//fmt.Printf("Magnetization sum %e, absErr %e\n", sum, absErr)
-	sum, _ := seriesaccel.Levin_u(MSumTerm, 1, 20)
-	x2, err := X2(env)
+	a := LandauA(ax, ay)
+	M, err := magnetizationLandauSum(env, a, b, env.Mu_b)
 	if err != nil {
 		return 0.0, err
 	}
-	return -a*x2 + sum/math.Pi, nil
-	//return -a*x2 - sum/math.Pi, nil
+	if axM, ayM, bM, muM, minus := omegaMinusCoeffs(env); minus {
+		MMinus, err := magnetizationLandauSum(env, LandauA(axM, ayM), bM, env.Mu_b+muM)
+		if err != nil {
+			return 0.0, err
+		}
+		M += MMinus
+	}
+	return M, nil
 }
 
 // Equivalent to Magnetization(); for use as YFunc in a plots.GraphVars
@@ -73,3 +48,78 @@ func GetMagnetization(data interface{}) float64 {
 	}
 	return M
 }
+
+// Magnetic susceptibility dM/dB per unit area divided by e^2, with Mu_b,
+// Mu_h, D1 and Beta held fixed. The Bessel sums for x_2 and M are
+// differentiated term by term, using their small-field expansions where
+// the closed forms lose precision, so that Be_field = 0 gives the zero-field
+// limit chi = -(a/3pi) sum_r I0(2 b beta r) exp(beta r (Mu_b - 2b)). This
+// limit diverges as Mu_b -> 0, in which case -Inf is returned.
+func Susceptibility(env *tempAll.Environment) (float64, error) {
+	if -env.Mu_b > -2.0*env.Mu_h {
+		return 0.0, nil
+	}
+	if env.Be_field == 0.0 && env.Mu_b >= 0.0 {
+		return math.Inf(-1), nil
+	}
+	// find omega_+ coefficients
+	ax, ay, b, err := PairCoeffs(env)
+	if err != nil {
+		fmt.Println("suppressing error in susceptibility - cannot find pair spectrum")
+		return 0.0, nil
+	}
+	a := LandauA(ax, ay)
+	// With u = r beta omega_c, x_2 = (2B/pi) sum_r c_r g(u) and
+	// M = -a x_2 + (1/pi) sum_r c_r h(u) / (beta r), where c_r is the
+	// field-independent part of each term.
+	omega_c := 4.0 * env.Be_field * a
+	coeff := func(r float64) float64 {
+		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * r)
+		return I0 * math.Exp(env.Beta*r*(env.Mu_b-2.0*b))
+	}
+	x2Term := func(ri int) float64 {
+		r := float64(ri)
+		return coeff(r) * landauX2Deriv(r*env.Beta*omega_c)
+	}
+	MTerm := func(ri int) float64 {
+		r := float64(ri)
+		return coeff(r) * landauMDeriv(r*env.Beta*omega_c)
+	}
+	x2Sum, _ := seriesaccel.Levin_u(x2Term, 1, 20)
+	MSum, _ := seriesaccel.Levin_u(MTerm, 1, 20)
+	dx2 := 2.0 * x2Sum / math.Pi
+	return -a*dx2 + 4.0*a*MSum/math.Pi, nil
+}
+
+// d/du [u g(u)] where g(u) = 1/(2 sinh(u/2)); B dx_2/dB is built from this.
+func landauX2Deriv(u float64) float64 {
+	if math.Abs(u) < 1e-3 {
+		return -u/12.0 + 7.0*u*u*u/1440.0
+	}
+	s, c := math.Sinh(u/2.0), math.Cosh(u/2.0)
+	g := 1.0 / (2.0 * s)
+	dg := -c / (4.0 * s * s)
+	return g + u*dg
+}
+
+// h'(u) where h(u) = g(u) - u exp(-u/2) / (4 sinh^2(u/2)).
+func landauMDeriv(u float64) float64 {
+	if math.Abs(u) < 1e-3 {
+		return -1.0/12.0 - u/24.0 + 7.0*u*u/480.0
+	}
+	s, c := math.Sinh(u/2.0), math.Cosh(u/2.0)
+	e := math.Exp(-u / 2.0)
+	dg := -c / (4.0 * s * s)
+	dLandau := (e/(s*s) - (u/2.0)*e/(s*s) - u*e*c/(s*s*s)) / 4.0
+	return dg - dLandau
+}
+
+// Equivalent to Susceptibility(); for use as YFunc in a plots.GraphVars
+func GetSusceptibility(data interface{}) float64 {
+	env := data.(tempAll.Environment)
+	chi, err := Susceptibility(&env)
+	if err != nil {
+		panic(err)
+	}
+	return chi
+}
@@ -0,0 +1,53 @@
+package tempCrit
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+// The small-u expansions used by Susceptibility should agree with the
+// closed forms near the point where they are switched.
+func TestLandauDerivContinuity(t *testing.T) {
+	below, above := 0.999e-3, 1.001e-3
+	if math.Abs(landauX2Deriv(below)-landauX2Deriv(above)) > 1e-6 {
+		t.Fatalf("landauX2Deriv discontinuous: %v, %v", landauX2Deriv(below), landauX2Deriv(above))
+	}
+	if math.Abs(landauMDeriv(below)-landauMDeriv(above)) > 1e-6 {
+		t.Fatalf("landauMDeriv discontinuous: %v, %v", landauMDeriv(below), landauMDeriv(above))
+	}
+	if landauMDeriv(0.0) != -1.0/12.0 {
+		t.Fatalf("unexpected zero-field limit %v", landauMDeriv(0.0))
+	}
+}
+
+// Susceptibility should be the field derivative of Magnetization at fixed
+// Mu_b.
+func TestSusceptibilityDerivative(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.1, "Mu_h": -0.6, "Beta": 4.0, "Mu_b": -0.05, "A": 0.65, "Ay": 0.65, "B": 0.09, "FixedPairCoeffs": true, "PairCoeffsReady": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := 1e-4
+	for _, Be := range []float64{0.005, 0.02} {
+		env.Be_field = Be
+		chi, err := Susceptibility(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env.Be_field = Be + h
+		Mplus, err := Magnetization(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env.Be_field = Be - h
+		Mminus, err := Magnetization(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := (Mplus - Mminus) / (2.0 * h)
+		if math.Abs(chi-diff) > 1e-3*math.Abs(diff) {
+			t.Fatalf("chi = %v at Be_field = %v; finite difference of M gives %v", chi, Be, diff)
+		}
+	}
+}
@@ -18,7 +18,7 @@ type OmegaFunc func(*tempAll.Environment, vec.Vector) (float64, error)
 // omega(q) = ax*q_x^2 + ay*q_y^2 + b*q_z^2 - mu_b
 //
 // The returned vector has the values {ax, ay, b, mu_b}. Due to x<->y symmetry
-// we expect ax == ay.
+// we expect ax == ay unless the hopping is anisotropic.
 func OmegaFit(env *tempAll.Environment, fn OmegaFunc) (vec.Vector, error) {
 	var numRadial int
 	var startDistance float64
@@ -0,0 +1,245 @@
+package tempCrit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+import (
+	"github.com/tflovorn/scExplorer/parallel"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Points per side of the omega_+ grid used if Environment.PairSpectrumPoints
+// is not given.
+const DEFAULT_PAIR_SPECTRUM_POINTS = 16
+
+// Number of integration points per grid cell along each axis.
+const omegaGridRefine = 2
+
+// omega_+(q) tabulated over the first Brillouin zone, on the same points as
+// bzone uses: q_i = -pi + 2*pi*i/N. omega_+ is measured from the pair
+// chemical potential Mu_b used to build the grid, so the pair energy is
+// omega_+ + Mu_b.
+type OmegaGrid struct {
+	N      int
+	Omegas []float64 // omega_+(q_i, q_j, q_k) at (i*N + j)*N + k; NaN where there is no pole
+	Mu_b   float64
+}
+
+// Tabulate omega_+(q) for env on an N x N x N grid by solving
+// 1 - lambda_+(q, omega) = 0 at each point.
+func NewOmegaGrid(env *tempAll.Environment, N int) (*OmegaGrid, error) {
+	if N <= 0 {
+		return nil, fmt.Errorf("invalid omega_+ grid size %d", N)
+	}
+	grid := &OmegaGrid{N, make([]float64, N*N*N), env.Mu_b}
+	// Evaluate one point first so that shared caches are filled before
+	// running in parallel.
+	OmegaPlus(env, []float64{0.0, 0.0, 0.0})
+	F := func(i int, cerr chan<- error) {
+		qx := omegaGridPoint(N, i)
+		for j := 0; j < N; j++ {
+			qy := omegaGridPoint(N, j)
+			for k := 0; k < N; k++ {
+				qz := omegaGridPoint(N, k)
+				omega, err := OmegaPlus(env, []float64{qx, qy, qz})
+				if err != nil {
+					omega = math.NaN()
+				}
+				grid.Omegas[(i*N+j)*N+k] = omega
+			}
+		}
+		cerr <- nil
+	}
+	errs := parallel.Run(F, N)
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return grid, nil
+}
+
+// Value of the i'th grid coordinate.
+func omegaGridPoint(N, i int) float64 {
+	return -math.Pi + 2.0*math.Pi*float64(i)/float64(N)
+}
+
+// Interpolate omega_+(q) trilinearly between grid points. q is taken to be
+// periodic with period 2*pi. Returns NaN if any neighboring grid point has
+// no pole.
+func (g *OmegaGrid) Omega(q vec.Vector) float64 {
+	N := g.N
+	step := 2.0 * math.Pi / float64(N)
+	idx := make([]int, 3)
+	frac := make([]float64, 3)
+	for d := 0; d < 3; d++ {
+		pos := (q[d] + math.Pi) / step
+		fl := math.Floor(pos)
+		frac[d] = pos - fl
+		idx[d] = ((int(fl) % N) + N) % N
+	}
+	val := 0.0
+	for c := 0; c < 8; c++ {
+		weight := 1.0
+		corner := make([]int, 3)
+		for d := 0; d < 3; d++ {
+			if c&(1<<uint(d)) != 0 {
+				weight *= frac[d]
+				corner[d] = (idx[d] + 1) % N
+			} else {
+				weight *= 1.0 - frac[d]
+				corner[d] = idx[d]
+			}
+		}
+		if weight == 0.0 {
+			continue
+		}
+		val += weight * g.Omegas[(corner[0]*N+corner[1])*N+corner[2]]
+	}
+	return val
+}
+
+// Average F(omega_+(q)) over the zone, interpolating omega_+ onto a grid
+// omegaGridRefine times finer and offset by half a step so that q = 0 (where
+// the Bose function may diverge) is not sampled. Points without a pole
+// contribute nothing.
+func (g *OmegaGrid) Avg(F func(float64) float64) float64 {
+	M := omegaGridRefine * g.N
+	step := 2.0 * math.Pi / float64(M)
+	total := 0.0
+	q := make([]float64, 3)
+	for i := 0; i < M; i++ {
+		q[0] = -math.Pi + (float64(i)+0.5)*step
+		for j := 0; j < M; j++ {
+			q[1] = -math.Pi + (float64(j)+0.5)*step
+			for k := 0; k < M; k++ {
+				q[2] = -math.Pi + (float64(k)+0.5)*step
+				omega := g.Omega(q)
+				if math.IsNaN(omega) {
+					continue
+				}
+				total += F(omega)
+			}
+		}
+	}
+	return total / math.Pow(float64(M), 3.0)
+}
+
+// Points per side of the omega_+ grid for env.
+func pairSpectrumPoints(env *tempAll.Environment) int {
+	if env.PairSpectrumPoints == 0 {
+		return DEFAULT_PAIR_SPECTRUM_POINTS
+	}
+	return env.PairSpectrumPoints
+}
+
+// Identifies the omega_+ grid built by FixPairSpectrum for a fixed pair
+// spectrum.
+type pairSpectrumKey struct {
+	Ax, Ay, B float64
+	N         int
+}
+
+// omega_+ grids built by FixPairSpectrum, with pair energies
+// omega_+(q) + mu_b stored (so that each grid's Mu_b is 0). Grids are kept
+// here rather than in the Environment so that they are not part of its JSON
+// form; copies of an Environment share its grid.
+var pairSpectrumCache = make(map[pairSpectrumKey]*OmegaGrid)
+var pairSpectrumLock sync.Mutex
+
+func envPairSpectrumKey(env *tempAll.Environment) pairSpectrumKey {
+	ax, ay, b := EnvPairCoeffs(env)
+	return pairSpectrumKey{ax, ay, b, pairSpectrumPoints(env)}
+}
+
+// Store the pair spectrum fit {ax, ay, b, mu_b} returned by OmegaFit in env
+// and mark the spectrum as fixed. If env.NumericPairSpectrum is set, also
+// tabulate omega_+(q) over the zone for use by the fixed spectrum; this is
+// the only place the grid is built, so it is solved for once per fixed
+// spectrum and not inside the solvers.
+func FixPairSpectrum(env *tempAll.Environment, omegaFit vec.Vector) error {
+	SetPairCoeffs(env, omegaFit)
+	if env.NumericPairSpectrum {
+		g, err := NewOmegaGrid(env, pairSpectrumPoints(env))
+		if err != nil {
+			return err
+		}
+		for i, omega := range g.Omegas {
+			g.Omegas[i] = omega + g.Mu_b
+		}
+		g.Mu_b = 0.0
+		storePairSpectrum(env, g)
+	}
+	env.PairCoeffsReady = true
+	return nil
+}
+
+// Use g, which gives pair energies (g.Mu_b = 0), as the tabulated spectrum
+// for the fixed pair spectrum coefficients of env.
+func storePairSpectrum(env *tempAll.Environment, g *OmegaGrid) {
+	pairSpectrumLock.Lock()
+	defer pairSpectrumLock.Unlock()
+	pairSpectrumCache[envPairSpectrumKey(env)] = g
+}
+
+// True if the tabulated spectrum stored by FixPairSpectrum is used in place
+// of the quadratic fit. Before the spectrum is fixed (while solving for T_c)
+// the fit is used.
+func usePairSpectrumGrid(env *tempAll.Environment) bool {
+	return env.NumericPairSpectrum && env.FixedPairCoeffs && env.PairCoeffsReady && math.Abs(env.Be_field) < 1e-9
+}
+
+// The omega_+ grid stored by FixPairSpectrum for the fixed pair spectrum of
+// env. The stored values are pair energies, so the grid's Mu_b is 0. The
+// grid is not saved with env, so it is not available for an Environment
+// loaded from JSON until FixPairSpectrum is called again.
+func envOmegaGrid(env *tempAll.Environment) (*OmegaGrid, error) {
+	pairSpectrumLock.Lock()
+	defer pairSpectrumLock.Unlock()
+	g, ok := pairSpectrumCache[envPairSpectrumKey(env)]
+	if !ok {
+		ax, ay, b := EnvPairCoeffs(env)
+		return nil, fmt.Errorf("no pair spectrum tabulated for (ax, ay, b) = (%v, %v, %v) (see FixPairSpectrum)", ax, ay, b)
+	}
+	return g, nil
+}
+
+// Energy, measured from env.Mu_b, of a pair with tabulated omega_+ = omega.
+// For a grid built at env itself this is omega.
+func (g *OmegaGrid) excitation(env *tempAll.Environment, omega float64) float64 {
+	return omega + g.Mu_b - env.Mu_b
+}
+
+// Concentration of paired holons using the tabulated omega_+ spectrum.
+func X2Grid(env *tempAll.Environment, g *OmegaGrid) float64 {
+	return g.Avg(func(omega float64) float64 {
+		return 2.0 / math.Expm1(env.Beta*g.excitation(env, omega))
+	})
+}
+
+// U_2/N = 1/N \sum_k (\omega_+(k) + \mu_b) n_b(\omega_+(k)) using the
+// tabulated omega_+ spectrum.
+func PairEnergyGrid(env *tempAll.Environment, g *OmegaGrid) float64 {
+	return g.Avg(func(omega float64) float64 {
+		return (omega + g.Mu_b) / math.Expm1(env.Beta*g.excitation(env, omega))
+	})
+}
+
+// Compare x_2 found from the small-q quadratic fit to x_2 found from an
+// N x N x N omega_+ grid. Returns (x2Fit, x2Grid).
+func CompareOmegaGrid(env *tempAll.Environment, N int) (float64, float64, error) {
+	fitEnv := env.Copy()
+	fitEnv.NumericPairSpectrum = false
+	x2Fit, err := X2(fitEnv)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	g, err := NewOmegaGrid(env, N)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return x2Fit, X2Grid(env, g), nil
+}
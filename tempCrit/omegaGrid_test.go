@@ -0,0 +1,78 @@
+package tempCrit
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+// Interpolation should reproduce grid values and be linear between them.
+func TestOmegaGridInterpolation(t *testing.T) {
+	N := 4
+	g := &OmegaGrid{N, make([]float64, N*N*N), 0.0}
+	for i := 0; i < N; i++ {
+		for j := 0; j < N; j++ {
+			for k := 0; k < N; k++ {
+				g.Omegas[(i*N+j)*N+k] = float64(i) + 10.0*float64(j) + 100.0*float64(k)
+			}
+		}
+	}
+	q := []float64{omegaGridPoint(N, 1), omegaGridPoint(N, 2), omegaGridPoint(N, 3)}
+	if val := g.Omega(q); math.Abs(val-321.0) > 1e-12 {
+		t.Fatalf("grid point value %v; expected 321", val)
+	}
+	step := 2.0 * math.Pi / float64(N)
+	q = []float64{omegaGridPoint(N, 1) + 0.25*step, omegaGridPoint(N, 2) + 0.5*step, omegaGridPoint(N, 0)}
+	if val := g.Omega(q); math.Abs(val-26.25) > 1e-12 {
+		t.Fatalf("interpolated value %v; expected 26.25", val)
+	}
+}
+
+// With the quadratic spectrum tabulated on the grid, x_2 and U_2 from the
+// grid should match those from the fit at Mu_b < 0.
+func TestOmegaGridMatchesFit(t *testing.T) {
+	env := new(tempAll.Environment)
+	env.Beta, env.Mu_b, env.Mu_h = 5.0, -1.0, -0.99
+	env.A, env.Ay, env.B = 0.1, 0.1, 0.1
+	env.FixedPairCoeffs, env.PairCoeffsReady = true, true
+	x2Fit, err := X2(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	U2Fit, err := PairEnergy(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	N := 16
+	gridEnv := *env
+	gridEnv.NumericPairSpectrum = true
+	gridEnv.PairSpectrumPoints = N
+	g := &OmegaGrid{N, make([]float64, N*N*N), 0.0}
+	for i := 0; i < N; i++ {
+		qx := omegaGridPoint(N, i)
+		for j := 0; j < N; j++ {
+			qy := omegaGridPoint(N, j)
+			for k := 0; k < N; k++ {
+				qz := omegaGridPoint(N, k)
+				g.Omegas[(i*N+j)*N+k] = env.A*(qx*qx+qy*qy) + 2.0*env.B*(1.0-math.Cos(qz))
+			}
+		}
+	}
+	storePairSpectrum(&gridEnv, g)
+	x2Grid, err := X2(&gridEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	U2Grid, err := PairEnergy(&gridEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The grid also covers the zone corners cut off by the fit, which
+	// matters more for U_2.
+	if math.Abs(x2Grid-x2Fit)/x2Fit > 0.05 {
+		t.Fatalf("x2 from grid %v; from fit %v", x2Grid, x2Fit)
+	}
+	if math.Abs(U2Grid-U2Fit)/U2Fit > 0.1 {
+		t.Fatalf("U2 from grid %v; from fit %v", U2Grid, U2Fit)
+	}
+}
@@ -0,0 +1,248 @@
+package tempCrit
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bessel"
+	"github.com/tflovorn/scExplorer/integrate"
+	"github.com/tflovorn/scExplorer/seriesaccel"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Return the omega_- spectrum coefficients (ax, ay, b, mu_minus) if
+// env.OmegaMinusPoles is set and the omega_- poles can be found. The omega_-
+// pairs have chemical potential Mu_b + mu_minus.
+func omegaMinusCoeffs(env *tempAll.Environment) (float64, float64, float64, float64, bool) {
+	if !env.OmegaMinusPoles {
+		return 0.0, 0.0, 0.0, 0.0, false
+	}
+	minusCoeffs, err := OmegaFit(env, OmegaMinus)
+	if err != nil {
+		fmt.Println("failed to find omega_- coeffs")
+		return 0.0, 0.0, 0.0, 0.0, false
+	}
+	return minusCoeffs[0], minusCoeffs[1], minusCoeffs[2], minusCoeffs[3], true
+}
+
+// Integrand for x_2 with the cos(kz) spectrum in zero field, for pairs with
+// chemical potential Mu_b + mu_relative.
+func x2CosIntegrand(env *tempAll.Environment, b, mu_relative float64) func(float64, float64) float64 {
+	return func(y, kz float64) float64 {
+		bterm := 2.0 * b * (1.0 - math.Cos(kz))
+		return 2.0 / (math.Exp(y+env.Beta*(bterm-env.Mu_b-mu_relative)) - 1.0)
+	}
+}
+
+// Integrand for U_2 with the cos(kz) spectrum in zero field, for pairs with
+// chemical potential Mu_b + mu_relative.
+func pairEnergyCosIntegrand(env *tempAll.Environment, b, mu_relative float64) func(float64, float64) float64 {
+	return func(y, kz float64) float64 {
+		bterm := 2.0 * b * (1.0 - math.Cos(kz))
+		num := y/env.Beta + bterm - mu_relative
+		denom := math.Exp(y+env.Beta*(bterm-env.Mu_b-mu_relative)) - 1.0
+		return num / denom
+	}
+}
+
+// Number of pair Landau levels summed explicitly by x2LandauSum.
+const LANDAU_LEVELS = 16
+
+// x_2 at finite field for pairs with in-plane coefficient a, interlayer
+// coefficient b and chemical potential mu, summed over Landau levels:
+// (2B/pi) \sum_n F(n omega_c) with F as in landauOccupation.
+//
+// The lowest level disperses only along kz, so its occupation diverges like
+// (omega_c/2 - mu)^{-1/2} as mu approaches the edge omega_c/2, and when
+// beta*omega_c is small the Bessel series over r crosses over from
+// power-law to exponential decay too slowly to be accelerated reliably.
+// Instead the lowest LANDAU_LEVELS levels are summed directly and the rest
+// by the Euler-Maclaurin formula. An error is returned if mu is at or above
+// the edge, where x_2 is infinite.
+func x2LandauSum(env *tempAll.Environment, a, b, mu float64) (float64, error) {
+	omega_c := 4.0 * env.Be_field * a
+	mu_tilde := mu - omega_c/2.0
+	if mu_tilde >= 0.0 {
+		return math.Inf(1), fmt.Errorf("pair chemical potential %v is not below the lowest Landau level edge %v; x2 diverges", mu, omega_c/2.0)
+	}
+	sum := 0.0
+	for n := 0; n < LANDAU_LEVELS; n++ {
+		F, err := landauOccupation(env, b, float64(n)*omega_c, mu_tilde)
+		if err != nil {
+			return 0.0, err
+		}
+		sum += F
+	}
+	E0 := float64(LANDAU_LEVELS) * omega_c
+	F0, err := landauOccupation(env, b, E0, mu_tilde)
+	if err != nil {
+		return 0.0, err
+	}
+	dF0, err := landauOccupationDeriv(env, b, E0, mu_tilde, 1)
+	if err != nil {
+		return 0.0, err
+	}
+	d3F0, err := landauOccupationDeriv(env, b, E0, mu_tilde, 3)
+	if err != nil {
+		return 0.0, err
+	}
+	tail, err := landauOccupationTail(env, b, E0, mu_tilde)
+	if err != nil {
+		return 0.0, err
+	}
+	sum += tail/omega_c + F0/2.0 - omega_c*dF0/12.0 + math.Pow(omega_c, 3.0)*d3F0/720.0
+	return 2.0 * env.Be_field * sum / math.Pi, nil
+}
+
+// Zero-field limit of x2LandauSum, with mu measured from the lowest Landau
+// level: the unquantized x_2 with no high-energy cutoff,
+// \int_0^\infty F(E) dE / (2 pi a).
+func x2Unquantized(env *tempAll.Environment, a, b, mu_tilde float64) (float64, error) {
+	tail, err := landauOccupationTail(env, b, 0.0, mu_tilde)
+	if err != nil {
+		return 0.0, err
+	}
+	return tail / (2.0 * math.Pi * a), nil
+}
+
+// Mean Bose occupation of the states of the pair Landau level at energy E
+// above the lowest one,
+// F(E) = (1/pi) \int_0^pi dkz n_b(E + 2b(1 - cos kz) - mu_tilde),
+// for mu_tilde < 0.
+func landauOccupation(env *tempAll.Environment, b, E, mu_tilde float64) (float64, error) {
+	return kzAverage(b, E-mu_tilde, func(eps float64) float64 {
+		return 1.0 / math.Expm1(env.Beta*eps)
+	})
+}
+
+// The first (order = 1) or third (order = 3) derivative with respect to E
+// of F as in landauOccupation.
+func landauOccupationDeriv(env *tempAll.Environment, b, E, mu_tilde float64, order int) (float64, error) {
+	return kzAverage(b, E-mu_tilde, func(eps float64) float64 {
+		// derivatives of n = n_b(eps) in terms of n
+		n := 1.0 / math.Expm1(env.Beta*eps)
+		if order == 1 {
+			return -env.Beta * n * (1.0 + n)
+		}
+		return -math.Pow(env.Beta, 3.0) * n * (1.0 + n) * (1.0 + 6.0*n + 6.0*n*n)
+	})
+}
+
+// \int_{E0}^\infty F(E) dE for F as in landauOccupation.
+func landauOccupationTail(env *tempAll.Environment, b, E0, mu_tilde float64) (float64, error) {
+	return kzAverage(b, E0-mu_tilde, func(eps float64) float64 {
+		return -math.Log(-math.Expm1(-env.Beta*eps)) / env.Beta
+	})
+}
+
+// (1/pi) \int_0^pi dkz fn(2b(1 - cos kz) + gap), for gap > 0. fn(eps) is
+// largest at small eps, so the integrand is peaked within
+// kz ~ sqrt(gap/b) of 0; the range is split there so that the peak is
+// resolved.
+func kzAverage(b, gap float64, fn func(float64) float64) (float64, error) {
+	integrand := func(kz float64) float64 {
+		return fn(2.0*b*(1.0-math.Cos(kz)) + gap)
+	}
+	if b == 0.0 {
+		return fn(gap), nil
+	}
+	k0 := math.Min(math.Pi, 10.0*math.Sqrt(gap/b))
+	eps := 1e-10
+	val := 0.0
+	for _, r := range [][2]float64{{0.0, k0}, {k0, math.Pi}} {
+		if r[1] <= r[0] {
+			continue
+		}
+		part, _, err := integrate.Qags(integrand, r[0], r[1], eps, eps)
+		if err != nil {
+			return 0.0, err
+		}
+		val += part
+	}
+	return val / math.Pi, nil
+}
+
+// U_2 at finite field for pairs with in-plane coefficient a, interlayer
+// coefficient b and chemical potential mu, summed over Landau levels.
+func pairEnergyLandauSum(env *tempAll.Environment, a, b, mu float64) float64 {
+	E2BSumTerm := func(ri int) float64 {
+		r := float64(ri)
+		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * r)
+		I1 := bessel.ModifiedBesselFirstKindFirst(2.0 * b * env.Beta * r)
+		omega_c := 4.0 * env.Be_field * a
+		mu_tilde := mu - omega_c/2.0
+		expL := math.Exp(r * env.Beta * (mu_tilde - 2.0*b))
+		expR := math.Exp(-env.Beta * omega_c * r)
+		expm1 := -math.Expm1(-env.Beta * omega_c * r)
+		return expL * ((I0*(0.5+2.0*b)-2.0*b*I1)*expm1 + (I0 * omega_c * expR * expm1 * expm1))
+	}
+	sum, _ := seriesaccel.Levin_u(E2BSumTerm, 1, 20)
+	return 2.0 * env.Be_field * sum / math.Pi
+}
+
+// Magnetization at finite field for pairs with in-plane coefficient a,
+// interlayer coefficient b and chemical potential mu, summed over Landau
+// levels.
+func magnetizationLandauSum(env *tempAll.Environment, a, b, mu float64) (float64, error) {
+	MSumTerm := func(ri int) float64 {
+		r := float64(ri)
+		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * r)
+		omega_c := 4.0 * env.Be_field * a
+		mu_tilde := mu - omega_c/2.0
+		exp := -math.Expm1(-r * env.Beta * omega_c)
+		bracket := 1.0/(env.Beta*r*exp) - omega_c*math.Exp(-r*env.Beta*omega_c)/(exp*exp)
+		return I0 * math.Exp(r*env.Beta*(mu_tilde-2.0*b)) * bracket
+	}
+	sum, _ := seriesaccel.Levin_u(MSumTerm, 1, 20)
+	x2, err := x2LandauSum(env, a, b, mu)
+	if err != nil {
+		return 0.0, err
+	}
+	return -a*x2 + sum/math.Pi, nil
+}
+
+// Summary of the omega_- pair branch, for checking that its contribution is
+// negligible.
+type OmegaMinusReport struct {
+	Found    bool       // false if no omega_- poles could be found
+	Coeffs   vec.Vector // {ax, ay, b, mu_minus} for omega_-
+	MuOffset float64    // mu_minus: omega_- pairs have chemical potential Mu_b + mu_minus
+	X2Plus   float64    // x_2 from omega_+ only
+	X2Minus  float64    // additional x_2 from omega_-
+}
+
+func (r *OmegaMinusReport) String() string {
+	if !r.Found {
+		return "no omega_- poles found"
+	}
+	return fmt.Sprintf("omega_- coeffs %v; mu offset %e; x2_+ = %e; x2_- = %e (ratio %e)", r.Coeffs, r.MuOffset, r.X2Plus, r.X2Minus, r.X2Minus/r.X2Plus)
+}
+
+// Fit the omega_- branch for env and find its contribution to x_2.
+func OmegaMinusDiagnostic(env *tempAll.Environment) (*OmegaMinusReport, error) {
+	report := &OmegaMinusReport{}
+	plusEnv := env.Copy()
+	plusEnv.OmegaMinusPoles = false
+	x2Plus, err := X2(plusEnv)
+	if err != nil {
+		return nil, err
+	}
+	report.X2Plus = x2Plus
+	coeffs, err := OmegaFit(env, OmegaMinus)
+	if err != nil {
+		return report, nil
+	}
+	report.Found = true
+	report.Coeffs = coeffs
+	report.MuOffset = coeffs[3]
+	minusEnv := env.Copy()
+	minusEnv.OmegaMinusPoles = true
+	x2Both, err := X2(minusEnv)
+	if err != nil {
+		return nil, err
+	}
+	report.X2Minus = x2Both - x2Plus
+	return report, nil
+}
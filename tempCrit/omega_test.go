@@ -33,6 +33,21 @@ func TestOmegaMinusSolution(t *testing.T) {
 	}
 }
 
+// Expect omega_- poles, if present, to make a negligible contribution to x_2.
+func TestOmegaMinusDiagnostic(t *testing.T) {
+	env, err := ctSolvedEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := OmegaMinusDiagnostic(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Found && math.Abs(report.X2Minus) > 1e-3*math.Abs(report.X2Plus) {
+		t.Fatalf("unexpectedly large omega_- contribution: %s", report.String())
+	}
+}
+
 // Check deviation of OmegaPlus from parabolic approximation (free particle).
 func TestOmegaPlusFitAccuracy(t *testing.T) {
 	env, err := ctSolvedEnv()
@@ -0,0 +1,42 @@
+package tempCrit
+
+import "math"
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Effective in-plane coefficient sqrt(ax*ay) of the pair spectrum, which
+// sets the Landau level spacing omega_c = 4*Be_field*a and the in-plane
+// density of states.
+func LandauA(ax, ay float64) float64 {
+	return math.Sqrt(ax * ay)
+}
+
+// Return the pair spectrum coefficients (ax, ay, b) stored in env.
+func EnvPairCoeffs(env *tempAll.Environment) (float64, float64, float64) {
+	ay := env.Ay
+	if ay == 0.0 {
+		ay = env.A
+	}
+	return env.A, ay, env.B
+}
+
+// Store the pair spectrum fit {ax, ay, b, mu_b} returned by OmegaFit in env.
+func SetPairCoeffs(env *tempAll.Environment, omegaFit vec.Vector) {
+	env.A, env.Ay, env.B = omegaFit[0], omegaFit[1], omegaFit[2]
+}
+
+// Return the omega_+ coefficients (ax, ay, b) for env: the stored values if
+// they are fixed, otherwise a fresh fit.
+func PairCoeffs(env *tempAll.Environment) (float64, float64, float64, error) {
+	if env.FixedPairCoeffs && env.PairCoeffsReady {
+		ax, ay, b := EnvPairCoeffs(env)
+		return ax, ay, b, nil
+	}
+	plusCoeffs, err := OmegaFit(env, OmegaPlus)
+	if err != nil {
+		return 0.0, 0.0, 0.0, err
+	}
+	return plusCoeffs[0], plusCoeffs[1], plusCoeffs[2], nil
+}
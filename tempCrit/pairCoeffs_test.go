@@ -0,0 +1,35 @@
+package tempCrit
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+// An unset Ay means an isotropic spectrum.
+func TestEnvPairCoeffsIsotropic(t *testing.T) {
+	env := &tempAll.Environment{A: 0.5, B: 0.1}
+	ax, ay, b := EnvPairCoeffs(env)
+	if ax != 0.5 || ay != 0.5 || b != 0.1 {
+		t.Fatalf("unexpected coefficients (%v, %v, %v)", ax, ay, b)
+	}
+}
+
+// Away from the zone edge cutoff, pair integrals should depend on ax and ay
+// only through sqrt(ax*ay).
+func TestOmegaIntegralCosAnisotropic(t *testing.T) {
+	env := &tempAll.Environment{Beta: 10.0, Mu_h: -0.1, Mu_b: -0.01}
+	b := 0.05
+	F := x2CosIntegrand(env, b, 0.0)
+	iso, err := OmegaIntegralCos(env, 2.0, 2.0, b, F)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aniso, err := OmegaIntegralCos(env, 1.0, 4.0, b, F)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(iso-aniso) > 1e-9*math.Abs(iso) {
+		t.Fatalf("anisotropic integral %v differs from isotropic %v", aniso, iso)
+	}
+}
@@ -10,9 +10,11 @@ import (
 )
 
 // Evaluate the retarded pair Green's function Pi_R(k, omega)_{xx, xy, yy}.
-// k must be a two-dimensional vector.
+// k must be a two-dimensional vector. If the holon dispersion depends on kz,
+// the sum over q includes q_z (with zero pair momentum along z).
 func Pi(env *tempAll.Environment, k vec.Vector, omega float64) vec.Vector {
 	var piInner func(k vec.Vector, out *vec.Vector)
+	dim := env.HolonDim()
 	// TODO: should this comparison be math.Abs(env.F0)? Not using that to
 	// avoid going to finite F0 procedure when F0 < 0 (since F0 is
 	// positive by choice of gauge). Also - would it be better to just
@@ -24,10 +26,16 @@ func Pi(env *tempAll.Environment, k vec.Vector, omega float64) vec.Vector {
 			// out = k/2 + q
 			(*out)[0] = k[0]/2.0 + q[0]
 			(*out)[1] = k[1]/2.0 + q[1]
+			if dim == 3 {
+				(*out)[2] = q[2]
+			}
 			xp := env.Xi_h(*out)
 			// out = k/2 - q
 			(*out)[0] = k[0]/2.0 - q[0]
 			(*out)[1] = k[1]/2.0 - q[1]
+			if dim == 3 {
+				(*out)[2] = -q[2]
+			}
 			xm := env.Xi_h(*out)
 
 			tp := math.Tanh(env.Beta * xp / 2.0)
@@ -45,11 +53,17 @@ func Pi(env *tempAll.Environment, k vec.Vector, omega float64) vec.Vector {
 			// out = k/2 + q
 			(*out)[0] = k[0]/2.0 + q[0]
 			(*out)[1] = k[1]/2.0 + q[1]
+			if dim == 3 {
+				(*out)[2] = q[2]
+			}
 			xi1 := env.Xi_h(*out)
 			E1 := env.BogoEnergy(*out)
 			// out = k/2 - q
 			(*out)[0] = k[0]/2.0 - q[0]
 			(*out)[1] = k[1]/2.0 - q[1]
+			if dim == 3 {
+				(*out)[2] = -q[2]
+			}
 			xi2 := env.Xi_h(*out)
 			E2 := env.BogoEnergy(*out)
 
@@ -68,5 +82,5 @@ func Pi(env *tempAll.Environment, k vec.Vector, omega float64) vec.Vector {
 			(*out)[2] = sy * sy * common
 		}
 	}
-	return bzone.VectorAvg(env.PointsPerSide, 2, 3, piInner)
+	return bzone.VectorAvg(env.PointsPerSide, dim, 3, piInner)
 }
@@ -5,13 +5,19 @@ import (
 	"math"
 )
 import (
-	"github.com/tflovorn/scExplorer/bessel"
-	"github.com/tflovorn/scExplorer/seriesaccel"
 	"github.com/tflovorn/scExplorer/tempAll"
 )
 
 // Concentration of paired holons
 func X2(env *tempAll.Environment) (float64, error) {
+	// tabulated spectrum - incompatible with finite magnetic field
+	if usePairSpectrumGrid(env) {
+		g, err := envOmegaGrid(env)
+		if err != nil {
+			return 0.0, err
+		}
+		return X2Grid(env, g), nil
+	}
 	// kz^2 version - incompatible with finite magnetic field
 	if env.PairKzSquaredSpectrum && math.Abs(env.Be_field) < 1e-9 {
 		nu, err := nu(env)
@@ -26,54 +32,68 @@ func X2(env *tempAll.Environment) (float64, error) {
 		return 0.0, nil
 	}
 	// find omega_+ coefficients
-	a, b := env.A, env.B
-	if !env.FixedPairCoeffs || !env.PairCoeffsReady {
-		plusCoeffs, err := OmegaFit(env, OmegaPlus)
-		//fmt.Printf("plusCoeffs in X2: %v\n", plusCoeffs)
-		if err != nil {
-			fmt.Println("suppressing error in x2 - cannot find pair spectrum")
-			return 0.0, nil
-		}
-		a, b = plusCoeffs[0], plusCoeffs[2]
+	ax, ay, b, err := PairCoeffs(env)
+	if err != nil {
+		fmt.Println("suppressing error in x2 - cannot find pair spectrum")
+		return 0.0, nil
 	}
+	axM, ayM, bM, muM, minus := omegaMinusCoeffs(env)
 	// zero magnetic field with cos(kz) spectrum
 	if math.Abs(env.Be_field) < 1e-9 {
-		integrand := func(y, kz float64) float64 {
-			bterm := 2.0 * b * (1.0 - math.Cos(kz))
-			return 2.0 / (math.Exp(y+env.Beta*(bterm-env.Mu_b)) - 1.0)
-		}
-		plus, err := OmegaIntegralCos(env, a, b, integrand)
+		plus, err := OmegaIntegralCos(env, ax, ay, b, x2CosIntegrand(env, b, 0.0))
 		if err != nil {
 			return 0.0, err
 		}
+		if minus {
+			minusVal, err := OmegaMinusIntegralCos(env, axM, ayM, bM, muM, x2CosIntegrand(env, bM, muM))
+			if err != nil {
+				return 0.0, err
+			}
+			return plus + minusVal, nil
+		}
 		return plus, nil
 	}
 	// if we get here, math.Abs(env.Be_field) >= 1e-9
-	//fmt.Printf("about to calculate x2 sum for env = %s\n", env.String())
-	x2BSumTerm := func(ri int) float64 {
-		r := float64(ri)
-		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * r)
-		omega_c := 4.0 * env.Be_field * a
-		mu_tilde := env.Mu_b - omega_c/2.0
-		return I0 * math.Exp(env.Beta*r*(mu_tilde-2.0*b)) / (-math.Expm1(-env.Beta * omega_c * r))
-		/*
-		sum_n_term := func(ni int) float64 {
-			n := float64(ni)
-			np1_fact := math.Gamma(n + 2.0)
-			return math.Pow(-r * env.Beta * omega_c, n) / np1_fact
+	x2, err := x2Field(env, ax, ay, b, 0.0)
+	if err != nil {
+		return 0.0, err
+	}
+	if minus {
+		x2Minus, err := x2Field(env, axM, ayM, bM, muM)
+		if err != nil {
+			return 0.0, err
 		}
-		sum_n, _ := seriesaccel.Levin_u(sum_n_term, 1, 20)
-		return I0 * math.Exp(env.Beta*r*(mu_tilde-2.0*b)) / (r * (1.0 + sum_n))
-		*/
-	}
-	sum, _ := seriesaccel.Levin_u(x2BSumTerm, 1, 20)
-	//fmt.Printf("%v\n", sum)
-	// reporting of absErr:
-	// (dropped this since absErr is always very small relative to sum)
-	//sum, absErr := seriesaccel.Levin_u(x2BSumTerm, 1, 20)
-	//fmt.Printf("for env=%s; x2 B sum %e, absErr %e\n", env.String(), sum, absErr)
-	return 2.0 * env.Be_field * sum / math.Pi, nil
-	//return sum / (2.0 * math.Pi * env.Beta * a), nil
+		x2 += x2Minus
+	}
+	return x2, nil
+}
+
+// x_2 at finite field for pairs with coefficients (ax, ay, b) and chemical
+// potential Mu_b + mu_relative.
+//
+// The sum over Landau levels runs over all in-plane energies, while the
+// zero-field integral stops at the two-holon continuum (or the zone edge).
+// The part of the unquantized spectrum above that cutoff, evaluated at the
+// chemical potential measured from the lowest Landau level, is subtracted
+// from the sum. Quantization only matters at low energy, so this leaves the
+// field dependence intact and makes x_2 continuous as Be_field -> 0.
+func x2Field(env *tempAll.Environment, ax, ay, b, mu_relative float64) (float64, error) {
+	a := LandauA(ax, ay)
+	sum, err := x2LandauSum(env, a, b, env.Mu_b+mu_relative)
+	if err != nil {
+		return 0.0, err
+	}
+	// chemical potential relative to Mu_b, measured from the lowest level
+	mu_tilde_relative := mu_relative - LandauEdge(env, a)
+	cut, err := omegaIntegralCosHelper(env, ax, ay, b, mu_tilde_relative, x2CosIntegrand(env, b, mu_tilde_relative))
+	if err != nil {
+		return 0.0, err
+	}
+	uncut, err := x2Unquantized(env, a, b, env.Mu_b+mu_tilde_relative)
+	if err != nil {
+		return 0.0, err
+	}
+	return sum - (uncut - cut), nil
 }
 
 // Equivalent to X2(); for use as YFunc in a plots.GraphVars
@@ -94,16 +114,12 @@ func nu(env *tempAll.Environment) (float64, error) {
 		return 2.0 * math.Sqrt(y) / (math.Exp(y-env.Beta*env.Mu_b) - 1.0)
 	}
 	// find omega_+ coefficients
-	a, b := env.A, env.B
-	if !env.FixedPairCoeffs || !env.PairCoeffsReady {
-		plusCoeffs, err := OmegaFit(env, OmegaPlus)
-		if err != nil {
-			fmt.Println("suppressing error in x2 - cannot find pair spectrum")
-			return 0.0, nil
-		}
-		a, b = plusCoeffs[0], plusCoeffs[2]
+	ax, ay, b, err := PairCoeffs(env)
+	if err != nil {
+		fmt.Println("suppressing error in x2 - cannot find pair spectrum")
+		return 0.0, nil
 	}
-	plus, err := OmegaIntegralY(env, a, b, integrand)
+	plus, err := OmegaIntegralY(env, ax, ay, b, integrand)
 	if err != nil {
 		return 0.0, err
 	}
@@ -116,7 +132,7 @@ func nu(env *tempAll.Environment) (float64, error) {
 			return plus, nil
 		}
 		fmt.Printf("got omega_- coeffs %v\n", minusCoeffs)
-		minus, err := OmegaMinusIntegralY(env, minusCoeffs[0], minusCoeffs[2], minusCoeffs[3], integrand)
+		minus, err := OmegaMinusIntegralY(env, minusCoeffs[0], minusCoeffs[1], minusCoeffs[2], minusCoeffs[3], integrand)
 		if err != nil {
 			return 0.0, err
 		}
@@ -124,3 +140,29 @@ func nu(env *tempAll.Environment) (float64, error) {
 	}
 	return plus, nil
 }
+
+// Energy of the lowest pair Landau level, omega_c/2 = 2*Be_field*a, measured
+// from the bottom of the zero-field omega_+ spectrum with in-plane coefficient
+// a (sqrt(ax*ay) if anisotropic; see LandauA). x_2 diverges as Mu_b
+// approaches this value (see LandauCondensedMu_b).
+func LandauEdge(env *tempAll.Environment, a float64) float64 {
+	omega_c := 4.0 * env.Be_field * a
+	return omega_c / 2.0
+}
+
+// Distance below the lowest Landau level edge, as a fraction of omega_c, at
+// which pairs are taken to condense in a field.
+const LANDAU_CONDENSATION_OFFSET = 0.1
+
+// Pair chemical potential at which pairs condense at finite field.
+//
+// The lowest Landau level disperses only along kz, so x_2 diverges as Mu_b
+// approaches LandauEdge and there is no sharp condensation in a field: any
+// x can be accommodated with Mu_b below the edge. Instead the condensate is
+// taken to form when Mu_b comes within LANDAU_CONDENSATION_OFFSET*omega_c of
+// the edge. Since the offset vanishes with the field, the resulting T_c(B)
+// and Hc2 approach the zero-field T_c as B -> 0.
+func LandauCondensedMu_b(env *tempAll.Environment, a float64) float64 {
+	omega_c := 4.0 * env.Be_field * a
+	return LandauEdge(env, a) - LANDAU_CONDENSATION_OFFSET*omega_c
+}
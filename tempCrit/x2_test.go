@@ -0,0 +1,83 @@
+package tempCrit
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/bessel"
+	"github.com/tflovorn/scExplorer/tempAll"
+)
+
+// Away from the lowest Landau level edge, x2LandauSum should match the Bessel
+// series summed directly. Approaching the edge it should grow without bound,
+// and at the edge it should fail.
+func TestX2LandauSum(t *testing.T) {
+	env := &tempAll.Environment{Beta: 4.0, Be_field: 0.01}
+	a, b := 0.65, 0.09
+	omega_c := 4.0 * env.Be_field * a
+	mu_tilde := -0.2
+	direct := 0.0
+	for r := 1; r < 200; r++ {
+		rf := float64(r)
+		I0 := bessel.ModifiedBesselFirstKindZeroth(2.0 * b * env.Beta * rf)
+		direct += I0 * math.Exp(env.Beta*rf*(mu_tilde-2.0*b)) / (-math.Expm1(-env.Beta * omega_c * rf))
+	}
+	direct *= 2.0 * env.Be_field / math.Pi
+	x2, err := x2LandauSum(env, a, b, mu_tilde+omega_c/2.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(x2-direct) > 1e-8*direct {
+		t.Fatalf("x2LandauSum = %v; direct sum gives %v", x2, direct)
+	}
+	// The lowest level contributes ~ (omega_c/2 - mu)^{-1/2}.
+	last := x2
+	for _, mu_tilde := range []float64{-1e-2, -1e-4, -1e-6} {
+		x2, err := x2LandauSum(env, a, b, mu_tilde+omega_c/2.0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x2 <= last {
+			t.Fatalf("x2 = %v at mu_tilde = %v does not increase toward the Landau edge (previous %v)", x2, mu_tilde, last)
+		}
+		last = x2
+	}
+	if last < 1.0 {
+		t.Fatalf("x2 = %v does not diverge at the Landau edge", last)
+	}
+	_, err = x2LandauSum(env, a, b, omega_c/2.0)
+	if err == nil {
+		t.Fatalf("expected error for Mu_b at the Landau edge")
+	}
+}
+
+// With Mu_b pinned to LandauCondensedMu_b, x2 should approach its zero-field
+// value at Mu_b = 0 as the field vanishes.
+func TestX2FieldLimit(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.1, "Mu_h": -0.6, "Beta": 4.0, "Mu_b": 0.0, "A": 0.65, "Ay": 0.65, "B": 0.09, "FixedPairCoeffs": true, "PairCoeffsReady": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x20, err := X2(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDiff := math.Inf(1)
+	for _, Be := range []float64{1e-4, 1e-6, 1e-8} {
+		env.Be_field = Be
+		env.Mu_b = LandauCondensedMu_b(env, LandauA(env.A, env.Ay))
+		x2, err := X2(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := math.Abs(x2 - x20)
+		if diff >= lastDiff {
+			t.Fatalf("x2 = %v at Be_field = %v does not approach zero-field value %v", x2, Be, x20)
+		}
+		lastDiff = diff
+	}
+	if lastDiff > 1e-3*x20 {
+		t.Fatalf("x2 differs from zero-field value %v by %v at small field", x20, lastDiff)
+	}
+}
@@ -18,8 +18,10 @@ func EnvSplitTcB(baseEnv *tempAll.Environment, TcFactors, BeFields []float64, ep
 	if err != nil {
 		return nil, err
 	}
-	TcEnv.A, TcEnv.B = omegaFit[0], omegaFit[2]
-	TcEnv.PairCoeffsReady = true
+	err = tempCrit.FixPairSpectrum(TcEnv, omegaFit)
+	if err != nil {
+		return nil, err
+	}
 
 	result := []*tempAll.Environment{}
 	for _, TcFactor := range TcFactors {
@@ -0,0 +1,211 @@
+package tempFluc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/solve"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempPair"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Upper critical field as a function of temperature, from T_c downward.
+type Hc2Result struct {
+	Tc        float64   // zero-field critical temperature
+	Temps     []float64 // temperatures at which Hc2 was found
+	Fields    []float64 // Hc2 (as Be_field) at each of Temps
+	Errs      []error   // errors produced while finding Fields[i]
+	SlopeAtTc float64   // dHc2/dT at T_c
+}
+
+// Calculate x - (x_1 + x_2) with Mu_b pinned to its condensation value
+// (tempCrit.LandauCondensedMu_b), as a function of Be_field. D1 and Mu_h are
+// solved for each field. This is positive for fields below Hc2 (condensate
+// present) and negative above.
+func AbsErrorHc2(env *tempAll.Environment, epsAbs, epsRel float64) solve.Diffable {
+	F := func(v vec.Vector) (float64, error) {
+		if v.ContainsNaN() {
+			fmt.Printf("got NaN in AbsErrorHc2 (v=%v)\n", v)
+			return 0.0, errors.New("NaN in input")
+		}
+		env.Be_field = v[0]
+		return landauPinnedError(env, epsAbs, epsRel)
+	}
+	h := 1e-5
+	epsabs := 1e-4
+	return solve.SimpleDiffable(F, 1, h, epsabs)
+}
+
+// Calculate x - (x_1 + x_2) with Mu_b pinned to its condensation value
+// (tempCrit.LandauCondensedMu_b) at the current Be_field and Beta, after
+// solving for D1 and Mu_h. Uses the fixed pair spectrum coefficients. Zero
+// at the onset of condensation.
+func landauPinnedError(env *tempAll.Environment, epsAbs, epsRel float64) (float64, error) {
+	ax, ay, _ := tempCrit.EnvPairCoeffs(env)
+	env.Mu_b = tempCrit.LandauCondensedMu_b(env, tempCrit.LandauA(ax, ay))
+	_, err := SolveD1Mu_h(env, epsAbs, epsRel)
+	if err != nil {
+		return 0.0, err
+	}
+	x1 := tempPair.X1(env)
+	x2, err := tempCrit.X2(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return env.X - (x1 + x2), nil
+}
+
+// Find the upper critical field Hc2 (as Be_field) at the temperature given
+// by env.Beta, which must be below T_c. The pair spectrum coefficients must
+// be fixed (env.FixedPairCoeffs and env.PairCoeffsReady set), as done by
+// Hc2Curve. Be_guess > 0 gives the initial upper bound for the search.
+// On success, env is left in the solved state at Be_field = Hc2.
+func Hc2(env *tempAll.Environment, Be_guess, epsAbs, epsRel float64) (float64, error) {
+	if !env.FixedPairCoeffs || !env.PairCoeffsReady {
+		return 0.0, fmt.Errorf("Hc2 requires fixed pair spectrum coefficients")
+	}
+	err := checkFieldSpectrum(env)
+	if err != nil {
+		return 0.0, err
+	}
+	diff := AbsErrorHc2(env, epsAbs, epsRel)
+	// Zero field: positive unless we are above T_c.
+	lo := 0.0
+	errLo, err := diff.F([]float64{lo})
+	if err != nil {
+		return 0.0, err
+	}
+	if errLo <= 0.0 {
+		return 0.0, fmt.Errorf("no condensate at zero field; T = %f is not below T_c", 1.0/env.Beta)
+	}
+	// Expand upper bound until the condensate vanishes.
+	hi := Be_guess
+	maxExpand := 20
+	for i := 0; ; i++ {
+		errHi, err := diff.F([]float64{hi})
+		if err != nil {
+			return 0.0, err
+		}
+		if errHi < 0.0 {
+			break
+		}
+		if i == maxExpand {
+			return 0.0, fmt.Errorf("failed to bracket Hc2 (error %e at Be_field = %e)", errHi, hi)
+		}
+		lo = hi
+		hi *= 2.0
+	}
+	Hc2, err := solve.Brent(diff, lo, hi, epsAbs, epsRel)
+	if err != nil {
+		return 0.0, err
+	}
+	// leave env in solved state
+	_, err = diff.F([]float64{Hc2})
+	if err != nil {
+		return 0.0, err
+	}
+	return Hc2, nil
+}
+
+// Trace Hc2(T) at T = TcFactor*T_c for each of TcFactors (which should be
+// in (0, 1)), and find the slope dHc2/dT at T_c.
+func Hc2Curve(baseEnv *tempAll.Environment, TcFactors []float64, epsAbs, epsRel float64) (*Hc2Result, error) {
+	err := checkFieldSpectrum(baseEnv)
+	if err != nil {
+		return nil, err
+	}
+	TcEnv, err := critTempPairFixed(baseEnv, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	Tc := 1.0 / TcEnv.Beta
+	result := &Hc2Result{Tc: Tc, Temps: make([]float64, len(TcFactors)), Fields: make([]float64, len(TcFactors)), Errs: make([]error, len(TcFactors))}
+	// Proceed away from T_c so that each search starts near the last field.
+	last := TcEnv
+	Be_guess := 1e-3
+	for _, i := range orderFromOne(TcFactors) {
+		env := last.Copy()
+		T := TcFactors[i] * Tc
+		env.Beta = 1.0 / T
+		env.Temp = T
+		result.Temps[i] = T
+		Hc2, err := Hc2(env, Be_guess, epsAbs, epsRel)
+		if err != nil {
+			result.Errs[i] = err
+			result.Fields[i] = math.NaN()
+			continue
+		}
+		result.Fields[i] = Hc2
+		last = env
+		Be_guess = 1.5 * Hc2
+	}
+	result.SlopeAtTc, err = Hc2SlopeAtTc(TcEnv, 1e-2, epsAbs, epsRel)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Estimate dHc2/dT at T_c from Hc2 at T = T_c*(1 - delta) and
+// T_c*(1 - 2*delta), using a least-squares line through (T_c, 0).
+// TcEnv must be solved at T_c with fixed pair spectrum coefficients.
+func Hc2SlopeAtTc(TcEnv *tempAll.Environment, delta, epsAbs, epsRel float64) (float64, error) {
+	Tc := 1.0 / TcEnv.Beta
+	num, denom := 0.0, 0.0
+	Be_guess := 1e-4
+	for _, f := range []float64{1.0 - delta, 1.0 - 2.0*delta} {
+		env := TcEnv.Copy()
+		env.Beta = 1.0 / (f * Tc)
+		Hc2, err := Hc2(env, Be_guess, epsAbs, epsRel)
+		if err != nil {
+			return 0.0, err
+		}
+		dT := f*Tc - Tc
+		num += dT * Hc2
+		denom += dT * dT
+		Be_guess = 1.5 * Hc2
+	}
+	return num / denom, nil
+}
+
+// Solve a copy of baseEnv at T_c in zero field and fix the pair spectrum
+// coefficients to their values there.
+func critTempPairFixed(baseEnv *tempAll.Environment, epsAbs, epsRel float64) (*tempAll.Environment, error) {
+	TcEnv := baseEnv.Copy()
+	TcEnv.Be_field = 0.0
+	TcEnv.Mu_b = 0.0
+	TcEnv.F0 = 0.0
+	_, err := tempCrit.CritTempSolve(TcEnv, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	omegaFit, err := tempCrit.OmegaFit(TcEnv, tempCrit.OmegaPlus)
+	if err != nil {
+		return nil, err
+	}
+	TcEnv.FixedPairCoeffs = true
+	err = tempCrit.FixPairSpectrum(TcEnv, omegaFit)
+	if err != nil {
+		return nil, err
+	}
+	return TcEnv, nil
+}
+
+// Return the indices of factors ordered by decreasing factor (closest to
+// 1 first).
+func orderFromOne(factors []float64) []int {
+	order := make([]int, len(factors))
+	for i := range order {
+		order[i] = i
+	}
+	for i := 1; i < len(order); i++ {
+		for j := i; j > 0 && factors[order[j]] > factors[order[j-1]]; j-- {
+			order[j], order[j-1] = order[j-1], order[j]
+		}
+	}
+	return order
+}
@@ -0,0 +1,103 @@
+package tempFluc
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/plots"
+
+var hc2Plot = flag.Bool("hc2Plot", false, "Plot Hc2(T)")
+
+// Factors should be visited from closest to 1 downward.
+func TestOrderFromOne(t *testing.T) {
+	order := orderFromOne([]float64{0.5, 0.9, 0.1, 0.7})
+	expected := []int{1, 3, 0, 2}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("incorrect order %v; expected %v", order, expected)
+		}
+	}
+}
+
+// Hc2 should vanish at T_c, grow as T decreases, and be the field at which
+// T_c(B) equals T.
+func TestHc2(t *testing.T) {
+	env, err := flucDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.PointsPerSide = 8
+	eps := 1e-6
+	TcEnv, err := critTempPairFixed(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Tc := 1.0 / TcEnv.Beta
+	above := TcEnv.Copy()
+	above.Beta = 1.0 / (1.05 * Tc)
+	_, err = Hc2(above, 1e-3, eps, eps)
+	if err == nil {
+		t.Fatalf("expected error finding Hc2 above T_c")
+	}
+	last := 0.0
+	for _, f := range []float64{0.95, 0.9} {
+		hEnv := TcEnv.Copy()
+		hEnv.Beta = 1.0 / (f * Tc)
+		H, err := Hc2(hEnv, 1e-3, eps, eps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if H <= last {
+			t.Fatalf("Hc2 = %v at T = %v T_c does not increase from %v", H, f, last)
+		}
+		last = H
+		bEnv := TcEnv.Copy()
+		bEnv.Be_field = H
+		_, err = critTempFieldFrom(bEnv, TcEnv, eps, eps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(1.0/bEnv.Beta-f*Tc) > 1e-5*Tc {
+			t.Fatalf("T_c(Hc2(T)) = %v; expected T = %v", 1.0/bEnv.Beta, f*Tc)
+		}
+	}
+}
+
+// Plot Hc2(T) from T_c downward.
+func TestPlotHc2(t *testing.T) {
+	flag.Parse()
+	if !*hc2Plot {
+		return
+	}
+	env, err := flucDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	TcFactors := []float64{0.95, 0.9, 0.8, 0.7, 0.6, 0.5}
+	eps := 1e-9
+	result, err := Hc2Curve(env, TcFactors, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Printf("Tc = %f; dHc2/dT at Tc = %f\n", result.Tc, result.SlopeAtTc)
+	Ts, Hs := []float64{result.Tc}, []float64{0.0}
+	for i, T := range result.Temps {
+		if result.Errs[i] != nil {
+			fmt.Println(result.Errs[i])
+			continue
+		}
+		Ts = append(Ts, T)
+		Hs = append(Hs, result.Fields[i])
+	}
+	wd, _ := os.Getwd()
+	grapherPath := wd + "/../plots/grapher.py"
+	graphParams := map[string]string{plots.FILE_KEY: wd + "/plot_data.Hc2_T", plots.XLABEL_KEY: "$T/t_0$", plots.YLABEL_KEY: "$eH_{c2}$"}
+	seriesParams := []map[string]string{map[string]string{"style": "k-"}}
+	err = plots.PlotMPL([]plots.Series{plots.MakeSeries(Ts, Hs)}, graphParams, seriesParams, grapherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,56 @@
+package tempFluc
+
+import "math"
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+)
+
+// Magnetization and susceptibility as a function of field at fixed
+// temperature.
+type MagnetizationCurve struct {
+	Fields []float64 // values of Be_field
+	M      []float64 // M/e at each of Fields (NaN if not found)
+	Chi    []float64 // dM/dB at fixed chemical potentials (NaN if not found)
+	Errs   []error   // errors produced while solving at Fields[i]
+}
+
+// Solve env at each field in BeFields using sv, and find M(B) and chi(B).
+// env should have its pair spectrum coefficients fixed (as in EnvSplitTcB).
+// B = 0 is solved like any other field: M is exactly zero there, and chi
+// takes its zero-field limit, which is -Inf if Mu_b = 0 (below T_c).
+func MagnetizationSweep(env *tempAll.Environment, BeFields []float64, epsAbs, epsRel float64, sv tempAll.Solver) *MagnetizationCurve {
+	N := len(BeFields)
+	curve := &MagnetizationCurve{BeFields, make([]float64, N), make([]float64, N), make([]error, N)}
+	envs := make([]*tempAll.Environment, N)
+	for i, Be := range BeFields {
+		envs[i] = env.Copy()
+		envs[i].Be_field = Be
+	}
+	solved, errs := tempAll.MultiSolve(envs, epsAbs, epsRel, sv)
+	for i := range BeFields {
+		curve.M[i], curve.Chi[i] = math.NaN(), math.NaN()
+		if errs[i] != nil {
+			curve.Errs[i] = errs[i]
+			continue
+		}
+		thisEnv := solved[i].(tempAll.Environment)
+		if BeFields[i] != 0.0 {
+			M, err := tempCrit.Magnetization(&thisEnv)
+			if err != nil {
+				curve.Errs[i] = err
+				continue
+			}
+			curve.M[i] = M
+		} else {
+			curve.M[i] = 0.0
+		}
+		chi, err := tempCrit.Susceptibility(&thisEnv)
+		if err != nil {
+			curve.Errs[i] = err
+			continue
+		}
+		curve.Chi[i] = chi
+	}
+	return curve
+}
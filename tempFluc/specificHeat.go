@@ -1,14 +1,12 @@
 package tempFluc
 
-import (
-	"fmt"
-	"math"
-)
 import (
 	"github.com/tflovorn/scExplorer/integrate"
 	"github.com/tflovorn/scExplorer/solve"
 	"github.com/tflovorn/scExplorer/tempAll"
 	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempPair"
+	vec "github.com/tflovorn/scExplorer/vector"
 )
 
 type SpecificHeatEnv struct {
@@ -24,24 +22,53 @@ func GetSHTemp(d interface{}) float64 {
 
 type envFunc func(*tempAll.Environment) (float64, error)
 
-// Specific heat at constant volume due to particles with energy U
+// Specific heat at constant volume due to particles with energy U,
+// C = (dU/dMu_h)_T (dMu_h/dT)_x + (dU/dT)_{Mu_h}. The derivatives of the
+// solved variables are found from the Jacobian of the converged equations
+// (implicit function theorem) instead of by re-solving at shifted T and Mu_h.
 func specificHeat(env *tempAll.Environment, U envFunc) (float64, error) {
-	// calculate first derivatives
-	MuT, err := dMu_hdT(env)
+	v := []float64{env.D1, env.Mu_h, env.Mu_b, env.X, env.Beta}
+	saved := make([]float64, len(v))
+	copy(saved, v)
+	// evaluating derivatives moves env away from the solution
+	defer env.Set(saved, shVariables)
+	jacobian, err := shSystem(env).Df(v)
 	if err != nil {
 		return 0.0, err
 	}
-	fmt.Println(MuT)
-	UMu, err := dFdMu_h(env, U)
+	// (D1, Mu_h, Mu_b) vary with T at fixed x. Mu_b = -Omega_+(0) holds
+	// whenever D1 and Mu_h are solved, so it cannot fix Mu_b; x = x_1 + x_2
+	// does.
+	dBeta, err := solve.ImplicitDerivatives(jacobian, []int{0, 1, 3}, []int{shD1, shMu_h, shMu_b}, []int{shBeta})
 	if err != nil {
 		return 0.0, err
 	}
-	fmt.Println(UMu)
-	UT, err := dFdT(env, U)
+	MuT := -env.Beta * env.Beta * dBeta[0][1]
+	// (D1, Mu_b, x) vary with Mu_h and T, as in SolveD1Mu_bX
+	unknowns := []int{shD1, shMu_b, shX}
+	dMuBeta, err := solve.ImplicitDerivatives(jacobian, []int{0, 2, 3}, unknowns, []int{shMu_h, shBeta})
 	if err != nil {
 		return 0.0, err
 	}
-	fmt.Println(UT)
+	UFn := func(v vec.Vector) (float64, error) {
+		env.Set(v, shVariables)
+		return U(env)
+	}
+	h, epsAbs := 1e-4, 1e-6
+	grad, err := solve.Gradient(UFn, v, h, epsAbs)
+	if err != nil {
+		return 0.0, err
+	}
+	// total derivatives of U with respect to Mu_h and Beta
+	total := func(j, param int) float64 {
+		d := grad[param]
+		for i, c := range unknowns {
+			d += grad[c] * dMuBeta[j][i]
+		}
+		return d
+	}
+	UMu := total(0, shMu_h)
+	UT := -env.Beta * env.Beta * total(1, shBeta)
 	return UMu*MuT + UT, nil
 }
 
@@ -63,93 +90,34 @@ func Entropy(temps, gammas []float64, lower, upper float64) (float64, error) {
 	return integrate.Spline(temps, gammas, lower, upper)
 }
 
-// Partial derivative of Mu_h with respect to T; x and V held constant.
-func dMu_hdT(env *tempAll.Environment) (float64, error) {
-	ct := 0
-	// F gets Mu_h given Beta
-	F := func(Beta float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oMu_h, oBeta, oMu_b := env.D1, env.Mu_h, env.Beta, env.Mu_b
-		env.Beta = Beta
-		// fix free variables
-		eps := 1e-9
-		_, err := SolveD1Mu_hMu_b(env, eps, eps)
-		if err != nil {
-			return 0.0, err
-		}
-		Mu_h := env.Mu_h
-		// restore the environment
-		env.D1, env.Mu_h, env.Beta, env.Mu_b = oD1, oMu_h, oBeta, oMu_b
-		return Mu_h, nil
-	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(F, env.Beta, h, epsAbs)
-	fmt.Println("dMu_dT ct", ct)
-	return -math.Pow(env.Beta, 2.0) * deriv, err
-}
+// Variables of shSystem, with their indices.
+var shVariables = []string{"D1", "Mu_h", "Mu_b", "X", "Beta"}
 
-// Partial derivative of F (some function of env) with respect to Mu_h;
-// T and V held constant.
-func dFdMu_h(env *tempAll.Environment, F envFunc) (float64, error) {
-	ct := 0
-	// G gets F given Mu_h (allow x to vary; constant Beta)
-	G := func(Mu_h float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oMu_h, oX, oMu_b := env.D1, env.Mu_h, env.X, env.Mu_b
-		env.Mu_h = Mu_h
-		// fix free variables
-		eps := 1e-9
-		_, err := SolveD1Mu_bX(env, eps, eps)
-		if err != nil {
-			return 0.0, err
-		}
-		vF, err := F(env)
-		if err != nil {
-			return 0.0, err
-		}
-		// restore the environment
-		env.D1, env.Mu_h, env.X, env.Mu_b = oD1, oMu_h, oX, oMu_b
-		return vF, nil
-	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(G, env.Mu_h, h, epsAbs)
-	fmt.Println("dF_dMu ct", ct)
-	return deriv, err
-}
+const (
+	shD1 = iota
+	shMu_h
+	shMu_b
+	shX
+	shBeta
+)
 
-// Partial derivative of F with respect to T; Mu_h and V held constant.
-func dFdT(env *tempAll.Environment, F envFunc) (float64, error) {
-	ct := 0
-	// G gets F given Beta (allow x to vary; constant Mu_h)
-	G := func(Beta float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oBeta, oX, oMu_b := env.D1, env.Beta, env.X, env.Mu_b
-		env.Beta = Beta
-		// fix free variables
-		eps := 1e-9
-		_, err := SolveD1Mu_bX(env, eps, eps)
-		if err != nil {
-			return 0.0, err
-		}
-		vF, err := F(env)
+// Equations of the T_c < T < T_p system as functions of shVariables, in the
+// order: D1, Mu_h (pair pole), Mu_b = -Omega_+(0), and x = x_1 + x_2. None of
+// these re-solve for other variables when evaluated.
+func shSystem(env *tempAll.Environment) solve.DiffSystem {
+	F := func(v vec.Vector) (float64, error) {
+		env.Set(v, shVariables)
+		x2, err := tempCrit.X2(env)
 		if err != nil {
 			return 0.0, err
 		}
-		// restore the environment
-		env.D1, env.Beta, env.X, env.Mu_b = oD1, oBeta, oX, oMu_b
-		return vF, nil
+		return env.X - tempPair.X1(env) - x2, nil
 	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(G, env.Beta, h, epsAbs)
-	fmt.Println("dF_dT ct", ct)
-	return -math.Pow(env.Beta, 2.0) * deriv, err
+	h := 1e-5
+	epsabs := 1e-4
+	diffX := solve.SimpleDiffable(F, len(shVariables), h, epsabs)
+	diffD1 := tempPair.AbsErrorD1(env, shVariables)
+	diffMu_h := AbsErrorMu_h(env, shVariables)
+	diffMu_b := AbsErrorMu_b(env, shVariables)
+	return solve.Combine([]solve.Diffable{diffD1, diffMu_h, diffMu_b, diffX})
 }
@@ -3,8 +3,13 @@ package tempFluc
 import (
 	"flag"
 	"fmt"
+	"math"
 	"testing"
 )
+import (
+	"github.com/tflovorn/scExplorer/solve"
+	"github.com/tflovorn/scExplorer/tempCrit"
+)
 
 var checkSH1 = flag.Bool("checkSH1", false, "run holon specific heat test")
 var checkSH2 = flag.Bool("checkSH2", false, "run pair specific heat test")
@@ -14,23 +19,7 @@ func TestHolonSpecificHeat(t *testing.T) {
 	if !*checkSH1 {
 		return
 	}
-
-	expected := 0.07335308942767277
-	env, err := flucDefaultEnv()
-	if err != nil {
-		t.Fatal(err)
-	}
-	env.D1 = defaultEnvSolution[0]
-	env.Mu_h = defaultEnvSolution[1]
-	env.Beta = defaultEnvSolution[2]
-	Cv1, err := HolonSpecificHeat(env)
-	fmt.Println(Cv1)
-	if err != nil {
-		t.Fatal(err)
-	}
-	if Cv1 != expected {
-		t.Fatalf("unexpected Cv1 value %v", Cv1)
-	}
+	checkSpecificHeat(t, HolonSpecificHeat, tempCrit.HolonEnergy)
 }
 
 func TestPairSpecificHeat(t *testing.T) {
@@ -38,8 +27,12 @@ func TestPairSpecificHeat(t *testing.T) {
 	if !*checkSH2 {
 		return
 	}
+	checkSpecificHeat(t, PairSpecificHeat, tempCrit.PairEnergy)
+}
 
-	expected := 0.09395423894897148
+// Compare the specific heat from the solution Jacobian to a central
+// difference of U between nested solutions at fixed x.
+func checkSpecificHeat(t *testing.T, specificHeat envFunc, U envFunc) {
 	env, err := flucDefaultEnv()
 	if err != nil {
 		t.Fatal(err)
@@ -47,12 +40,33 @@ func TestPairSpecificHeat(t *testing.T) {
 	env.D1 = defaultEnvSolution[0]
 	env.Mu_h = defaultEnvSolution[1]
 	env.Beta = defaultEnvSolution[2]
-	Cv2, err := PairSpecificHeat(env)
-	fmt.Println(Cv2)
+	Cv, err := specificHeat(env)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if Cv2 != expected {
-		t.Fatalf("unexpected Cv2 value %v", Cv2)
+	// Solve for Mu_b (and D1, Mu_h) at T with x fixed and return U there.
+	energy := func(T float64) float64 {
+		shifted := env.Copy()
+		shifted.Beta = 1.0 / T
+		diffMu_b := AbsErrorBeta(shifted, []string{"Mu_b"})
+		Mu_b, err := solve.Brent(diffMu_b, 2.0*env.Mu_b, 0.5*env.Mu_b, 1e-12, 1e-12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// leave shifted at the solution
+		if _, err = diffMu_b.F([]float64{Mu_b}); err != nil {
+			t.Fatal(err)
+		}
+		u, err := U(shifted)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return u
+	}
+	T, h := 1.0/env.Beta, 1e-3
+	expected := (energy(T+h) - energy(T-h)) / (2.0 * h)
+	fmt.Println(Cv, expected)
+	if math.Abs(Cv-expected) > 1e-5 {
+		t.Fatalf("specific heat %v differs from dU/dT = %v", Cv, expected)
 	}
 }
@@ -89,8 +89,10 @@ func FlucTempSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector
 		if err != nil {
 			return nil, err
 		}
-		env.A, env.B = omegaFit[0], omegaFit[2]
-		env.PairCoeffsReady = true
+		err = tempCrit.FixPairSpectrum(env, omegaFit)
+		if err != nil {
+			return nil, err
+		}
 		// uncache env
 		env.D1, env.Mu_h, env.Mu_b, env.Beta = D1, Mu_h, Mu_b, Beta
 	}
@@ -165,8 +167,10 @@ func SolveD1Mu_hMu_b(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vect
 		if err != nil {
 			return nil, err
 		}
-		env.A, env.B = omegaFit[0], omegaFit[2]
-		env.PairCoeffsReady = true
+		err = tempCrit.FixPairSpectrum(env, omegaFit)
+		if err != nil {
+			return nil, err
+		}
 		// uncache env
 		env.D1, env.Mu_h, env.Mu_b, env.Beta, env.Be_field = D1, Mu_h, Mu_b, Beta, Be_field
 	}
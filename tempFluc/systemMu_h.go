@@ -20,7 +20,7 @@ func AbsErrorMu_h(env *tempAll.Environment, variables []string) solve.Diffable {
 		}
 		L := env.PointsPerSide
 		lhs := 0.5 / (env.T0 + env.Tz)
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerMu_h))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerMu_h))
 		return lhs - rhs, nil
 	}
 	h := 1e-5
@@ -29,8 +29,7 @@ func AbsErrorMu_h(env *tempAll.Environment, variables []string) solve.Diffable {
 }
 
 func innerMu_h(env *tempAll.Environment, k vec.Vector) float64 {
-	sxy := math.Sin(k[0]) - math.Sin(k[1])
-	numer := sxy * sxy * math.Tanh(env.Beta*env.Xi_h(k)/2.0)
+	numer := env.GapWeight(k) * math.Tanh(env.Beta*env.Xi_h(k)/2.0)
 	denom := env.Mu_b + 2.0*env.Xi_h(k)
 	//denom := env.Mu_b - 2.0*env.Be_field*env.A + 2.0*env.Xi_h(k)
 	return numer / denom
@@ -0,0 +1,148 @@
+package tempFluc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/solve"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Critical temperature as a function of magnetic field.
+type TcBResult struct {
+	Tc0    float64   // zero-field critical temperature
+	Fields []float64 // values of Be_field
+	Tcs    []float64 // T_c(B) at each of Fields (NaN if not found)
+	Ratios []float64 // T_c(B) / T_c(0)
+	Errs   []error   // errors produced while finding Tcs[i]
+}
+
+// Calculate x - (x_1 + x_2) with Mu_b pinned to its condensation value
+// (tempCrit.LandauCondensedMu_b), as a function of Beta. D1 and Mu_h are
+// solved for each Beta. This is negative above T_c(B) and positive below.
+func AbsErrorTcB(env *tempAll.Environment, epsAbs, epsRel float64) solve.Diffable {
+	F := func(v vec.Vector) (float64, error) {
+		if v.ContainsNaN() {
+			fmt.Printf("got NaN in AbsErrorTcB (v=%v)\n", v)
+			return 0.0, errors.New("NaN in input")
+		}
+		env.Beta = v[0]
+		return landauPinnedError(env, epsAbs, epsRel)
+	}
+	h := 1e-5
+	epsabs := 1e-4
+	return solve.SimpleDiffable(F, 1, h, epsabs)
+}
+
+// Solve for T_c at the field env.Be_field, with the pair spectrum quantized
+// into Landau levels and Mu_b pinned to its condensation value just below
+// the lowest level (see tempCrit.LandauCondensedMu_b). If the pair spectrum
+// coefficients are not already fixed, they are fixed to their values at the
+// zero-field T_c. Returns (D1, Mu_h, Beta); suitable for use as a
+// tempAll.Solver.
+func CritTempFieldSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector, error) {
+	err := checkFieldSpectrum(env)
+	if err != nil {
+		return nil, err
+	}
+	// T_c(B) <= T_c(0), so start from the zero-field solution.
+	Tc0Env, err := critTempPairFixed(env, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	return critTempFieldFrom(env, Tc0Env, epsAbs, epsRel)
+}
+
+// Solve for T_c at the field env.Be_field as in CritTempFieldSolve, starting
+// from Tc0Env, the zero-field solution found by critTempPairFixed.
+func critTempFieldFrom(env, Tc0Env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector, error) {
+	Be_field := env.Be_field
+	Beta_c0 := Tc0Env.Beta
+	if !env.FixedPairCoeffs || !env.PairCoeffsReady {
+		env.A, env.Ay, env.B = Tc0Env.A, Tc0Env.Ay, Tc0Env.B
+		env.FixedPairCoeffs = true
+		env.PairCoeffsReady = true
+	}
+	env.D1, env.Mu_h, env.F0 = Tc0Env.D1, Tc0Env.Mu_h, 0.0
+	env.Be_field = Be_field
+	if math.Abs(Be_field) < 1e-9 {
+		env.Beta, env.Mu_b = Beta_c0, 0.0
+		return []float64{env.D1, env.Mu_h, env.Beta}, nil
+	}
+	diff := AbsErrorTcB(env, epsAbs, epsRel)
+	lo := Beta_c0
+	errLo, err := diff.F([]float64{lo})
+	if err != nil {
+		return nil, err
+	}
+	if errLo > 0.0 {
+		return nil, fmt.Errorf("field Be_field = %e raised T_c above its zero-field value", Be_field)
+	}
+	// Expand toward low temperature until the condensate appears.
+	hi := 1.1 * lo
+	maxExpand := 40
+	for i := 0; ; i++ {
+		errHi, err := diff.F([]float64{hi})
+		if err != nil {
+			return nil, err
+		}
+		if errHi > 0.0 {
+			break
+		}
+		if i == maxExpand {
+			return nil, fmt.Errorf("failed to bracket T_c(B) for Be_field = %e", Be_field)
+		}
+		lo = hi
+		hi *= 1.1
+	}
+	Beta, err := solve.Brent(diff, lo, hi, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	// leave env in solved state
+	_, err = diff.F([]float64{Beta})
+	if err != nil {
+		return nil, err
+	}
+	return []float64{env.D1, env.Mu_h, env.Beta}, nil
+}
+
+// Find T_c(B) for each field in BeFields and compare to the zero-field T_c.
+func TcBCurve(baseEnv *tempAll.Environment, BeFields []float64, epsAbs, epsRel float64) (*TcBResult, error) {
+	err := checkFieldSpectrum(baseEnv)
+	if err != nil {
+		return nil, err
+	}
+	Tc0Env, err := critTempPairFixed(baseEnv, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	Tc0 := 1.0 / Tc0Env.Beta
+	N := len(BeFields)
+	result := &TcBResult{Tc0, BeFields, make([]float64, N), make([]float64, N), make([]error, N)}
+	for i, Be := range BeFields {
+		env := Tc0Env.Copy()
+		env.Be_field = Be
+		_, err := critTempFieldFrom(env, Tc0Env, epsAbs, epsRel)
+		if err != nil {
+			result.Errs[i] = err
+			result.Tcs[i], result.Ratios[i] = math.NaN(), math.NaN()
+			continue
+		}
+		result.Tcs[i] = 1.0 / env.Beta
+		result.Ratios[i] = result.Tcs[i] / Tc0
+	}
+	return result, nil
+}
+
+// The tabulated pair spectrum (NumericPairSpectrum) is not Landau quantized,
+// so it cannot be used at finite field.
+func checkFieldSpectrum(env *tempAll.Environment) error {
+	if env.NumericPairSpectrum {
+		return fmt.Errorf("NumericPairSpectrum is incompatible with finite field; unset it to find T_c(B) or Hc2")
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package tempFluc
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/plots"
+
+var tcbPlot = flag.Bool("tcbPlot", false, "Plot T_c(B)/T_c(0) for several values of x and t_z")
+
+// T_c(B) should decrease with field and approach T_c(0) as B -> 0. The
+// lowest Landau level holds a density of pairs ~ sqrt(B) at condensation,
+// so 1 - T_c(B)/T_c(0) should scale as sqrt(B) at small field.
+func TestTcB(t *testing.T) {
+	env, err := flucDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.PointsPerSide = 8
+	eps := 1e-6
+	result, err := TcBCurve(env, []float64{0.0, 1e-6, 1e-4, 1e-2}, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range result.Ratios {
+		if result.Errs[i] != nil {
+			t.Fatal(result.Errs[i])
+		}
+		if i == 0 && r != 1.0 {
+			t.Fatalf("T_c(0) from TcBCurve differs from zero-field T_c: ratio %v", r)
+		}
+		if i > 0 && r >= result.Ratios[i-1] {
+			t.Fatalf("T_c(B) does not decrease with B: ratios %v at fields %v", result.Ratios, result.Fields)
+		}
+	}
+	if 1.0-result.Ratios[1] > 2e-3 {
+		t.Fatalf("T_c(B) does not approach T_c(0) at small field: ratio %v", result.Ratios[1])
+	}
+	scale := (1.0 - result.Ratios[2]) / (1.0 - result.Ratios[1])
+	if scale < 5.0 || scale > 20.0 {
+		t.Fatalf("T_c suppression grew by %v from B = 1e-6 to 1e-4; expected ~10 (sqrt(B))", scale)
+	}
+}
+
+// The tabulated pair spectrum is not Landau quantized, so finite-field
+// calculations should reject it before solving.
+func TestFieldRejectsNumericPairSpectrum(t *testing.T) {
+	env, err := flucDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.FixedPairCoeffs = true
+	env.NumericPairSpectrum = true
+	eps := 1e-6
+	if _, err := TcBCurve(env, []float64{0.0, 1e-4}, eps, eps); err == nil {
+		t.Fatalf("TcBCurve accepted NumericPairSpectrum")
+	}
+	if _, err := Hc2Curve(env, []float64{0.9}, eps, eps); err == nil {
+		t.Fatalf("Hc2Curve accepted NumericPairSpectrum")
+	}
+}
+
+// Plot T_c(B)/T_c(0) as a function of field, one curve per (x, t_z).
+func TestPlotTcB(t *testing.T) {
+	flag.Parse()
+	if !*tcbPlot {
+		return
+	}
+	env, err := flucDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	BeFields := []float64{0.0, 0.001, 0.002, 0.005, 0.01, 0.02}
+	envs := env.MultiSplit([]string{"X", "Tz"}, []int{3, 2}, []float64{0.05, 0.1}, []float64{0.15, 0.15})
+	eps := 1e-9
+	series := []plots.Series{}
+	seriesParams := []map[string]string{}
+	styles := []string{"k-", "r-", "b-", "g-", "c-", "m-"}
+	for i, e := range envs {
+		result, err := TcBCurve(e, BeFields, eps, eps)
+		if err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("x = %f, tz = %f: Tc(0) = %f, Tc(B) = %v\n", e.X, e.Tz, result.Tc0, result.Tcs)
+		Bs, ratios := []float64{}, []float64{}
+		for j, Be := range result.Fields {
+			if result.Errs[j] != nil {
+				fmt.Println(result.Errs[j])
+				continue
+			}
+			Bs = append(Bs, Be)
+			ratios = append(ratios, result.Ratios[j])
+		}
+		series = append(series, plots.MakeSeries(Bs, ratios))
+		label := fmt.Sprintf("$x=%.2f$, $t_z=%.2f$", e.X, e.Tz)
+		seriesParams = append(seriesParams, map[string]string{"style": styles[i%len(styles)], "label": label})
+	}
+	wd, _ := os.Getwd()
+	grapherPath := wd + "/../plots/grapher.py"
+	graphParams := map[string]string{plots.FILE_KEY: wd + "/plot_data.TcB", plots.XLABEL_KEY: "$eB$", plots.YLABEL_KEY: "$T_c(B)/T_c(0)$"}
+	err = plots.PlotMPL(series, graphParams, seriesParams, grapherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
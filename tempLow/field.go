@@ -0,0 +1,84 @@
+package tempLow
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// In a field, tempLow uses the pair spectrum fitted at the zero-field T_c,
+// omega_+(q) = a q_perp^2 + 2b(1 - cos(qz)) - Mu_b, quantized into Landau
+// levels as tempCrit does above T_c. Landau quantization of the Bogoliubov
+// pair spectrum (see X2) is not implemented. This matches the zero-field
+// calculation with the pair spectrum fixed at T_c (FixedPairCoeffs), which
+// does not use the Bogoliubov spectrum either.
+
+// Below T_c the pairs are condensed, so Mu_b sits at the bottom of the pair
+// spectrum: 0 at zero field, or at finite field the condensation value just
+// below the lowest Landau level omega_c/2 (see
+// tempCrit.LandauCondensedMu_b), where x_2 is finite. Set env.Mu_b
+// accordingly.
+func pinMu_b(env *tempAll.Environment) error {
+	if env.Be_field == 0.0 {
+		env.Mu_b = 0.0
+		return nil
+	}
+	ax, ay, _, err := tempCrit.PairCoeffs(env)
+	if err != nil {
+		return fmt.Errorf("cannot find pair spectrum to pin Mu_b: %v", err)
+	}
+	env.Mu_b = tempCrit.LandauCondensedMu_b(env, tempCrit.LandauA(ax, ay))
+	return nil
+}
+
+// Concentration of holons in the pair condensate,
+// x_c = <(|Delta_k| / 2E_k)^2 tanh^2(beta E_k / 2)>_k; zero when F0 = 0.
+func CondensateX(env *tempAll.Environment) float64 {
+	L := env.PointsPerSide
+	return bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerCondensateX))
+}
+
+func innerCondensateX(env *tempAll.Environment, k vec.Vector) float64 {
+	E := env.BogoEnergy(k)
+	t := math.Tanh(env.Beta*E/2.0) / (2.0 * E)
+	return env.DeltaAbs2_h(k) * t * t
+}
+
+// Magnetization per unit area divided by e below T_c. The uncondensed pairs
+// contribute as in tempCrit.Magnetization, using the quantized T_c spectrum
+// (not the Bogoliubov spectrum); condensed pairs occupy the lowest Landau
+// level, with energy omega_c/2 = 2 a B per pair, and contribute -a x_c.
+// Since x_c vanishes with F0, M(T) is continuous through T_c.
+func Magnetization(env *tempAll.Environment) (float64, error) {
+	if env.Be_field == 0.0 {
+		return 0.0, nil
+	}
+	err := pinMu_b(env)
+	if err != nil {
+		return 0.0, err
+	}
+	Mthermal, err := tempCrit.Magnetization(env)
+	if err != nil {
+		return 0.0, err
+	}
+	ax, ay, _, err := tempCrit.PairCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return Mthermal - tempCrit.LandauA(ax, ay)*CondensateX(env), nil
+}
+
+// Equivalent to Magnetization(); for use as YFunc in a plots.GraphVars
+func GetMagnetization(data interface{}) float64 {
+	env := data.(tempAll.Environment)
+	M, err := Magnetization(&env)
+	if err != nil {
+		panic(err)
+	}
+	return M
+}
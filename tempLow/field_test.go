@@ -0,0 +1,53 @@
+package tempLow
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+)
+
+// In a field, Mu_b should be pinned just below the lowest Landau level edge,
+// where x2 and the magnetization are finite, and x2 should approach its
+// zero-field value as the field vanishes.
+func TestFieldPinnedMu_b(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.01, "Mu_h": -0.5, "Beta": 4.0, "F0": 0.01, "A": 0.65, "Ay": 0.65, "B": 0.09, "FixedPairCoeffs": true, "PairCoeffsReady": true}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = pinMu_b(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x20, err := X2(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := tempCrit.LandauA(env.A, env.Ay)
+	for _, Be := range []float64{1e-3, 1e-8} {
+		env.Be_field = Be
+		err = pinMu_b(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if env.Mu_b != tempCrit.LandauCondensedMu_b(env, a) || env.Mu_b >= tempCrit.LandauEdge(env, a) {
+			t.Fatalf("Mu_b = %v not pinned below the Landau edge %v", env.Mu_b, tempCrit.LandauEdge(env, a))
+		}
+		x2, err := X2(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		M, err := Magnetization(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.IsInf(x2, 0) || math.IsNaN(x2) || math.IsInf(M, 0) || math.IsNaN(M) {
+			t.Fatalf("x2 = %v, M = %v not finite at Be_field = %v", x2, M, Be)
+		}
+		if Be == 1e-8 && math.Abs(x2-x20) > 1e-3*x20 {
+			t.Fatalf("x2 = %v at small field; zero-field value %v", x2, x20)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package tempLow
+
+import "math"
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Grand potential of holons below T_c in the mean-field (Bogoliubov) state,
+// Omega_1/N = 1/N \sum_k [(\xi_k - E_k)/2 - T ln(1 + e^{-\beta E_k})] +
+// 4 (T0 + Tz) F0^2.
+// The constant term makes Omega_1 stationary in F0 when the T = 0 gap
+// equation 1/(T0 + Tz) = <|g_k|^2/E_k> holds. Reduces to
+// tempCrit.HolonGrandPotential when F0 = 0.
+func HolonGrandPotential(env *tempAll.Environment) (float64, error) {
+	inner := func(k vec.Vector) float64 {
+		xi, E := env.Xi_h(k), env.BogoEnergy(k)
+		return (xi-E)/2.0 - math.Log1p(math.Exp(-env.Beta*E))/env.Beta
+	}
+	avg := bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+	return avg + 4.0*(env.T0+env.Tz)*env.F0*env.F0, nil
+}
+
+// Helmholtz free energy of holons below T_c, F_1/N = Omega_1/N + \mu_h x_1.
+func HolonFreeEnergy(env *tempAll.Environment) (float64, error) {
+	omega, err := HolonGrandPotential(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return omega + env.Mu_h*X1(env), nil
+}
+
+// Entropy of Bogoliubov quasiparticles,
+// S_1/N = -\partial(\Omega_1/N)/\partial T with Mu_h and F0 held fixed.
+func HolonEntropy(env *tempAll.Environment) (float64, error) {
+	if math.IsInf(env.Beta, 1) {
+		return 0.0, nil
+	}
+	inner := func(k vec.Vector) float64 {
+		return tempCrit.FermionEntropy(env.Beta * env.BogoEnergy(k))
+	}
+	return bzone.Avg(env.PointsPerSide, env.HolonDim(), inner), nil
+}
+
+// Grand potential of uncondensed pairs below T_c,
+// Omega_2/N = 1/N \sum_k [T ln(1 - e^{-\beta \omega(k)}) + (\omega(k) - left(k))/2],
+// using the Bogoliubov pair spectrum where X2 does. The zero-point term
+// matches PairEnergy and gives the quantum depletion in X2. Falls back to
+// tempCrit.PairGrandPotential in the same cases as X2.
+func PairGrandPotential(env *tempAll.Environment) (float64, error) {
+	cs, ok, err := bogoliubovCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	if !ok {
+		return tempCrit.PairGrandPotential(env)
+	}
+	integrand := func(y, kz float64) float64 {
+		left, omega := bogoliubovOmega(env, cs, y, kz)
+		return math.Log(-math.Expm1(-env.Beta*omega))/env.Beta + (omega-left)/2.0
+	}
+	return tempCrit.OmegaIntegralCos(env, cs[0], cs[1], cs[2], integrand)
+}
+
+// Helmholtz free energy of uncondensed pairs below T_c. With the Bogoliubov
+// spectrum, the pair chemical potential is pinned to the bottom of the
+// spectrum and F_2 = Omega_2; otherwise this is tempCrit.PairFreeEnergy.
+func PairFreeEnergy(env *tempAll.Environment) (float64, error) {
+	_, ok, err := bogoliubovCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	if !ok {
+		return tempCrit.PairFreeEnergy(env)
+	}
+	return PairGrandPotential(env)
+}
+
+// Entropy of uncondensed pairs below T_c, S_2/N = (U_2 - F_2)/(NT), with
+// the pair spectrum held fixed.
+func PairEntropy(env *tempAll.Environment) (float64, error) {
+	cs, ok, err := bogoliubovCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	if !ok {
+		return tempCrit.PairEntropy(env)
+	}
+	integrand := func(y, kz float64) float64 {
+		_, omega := bogoliubovOmega(env, cs, y, kz)
+		return tempCrit.BosonEntropy(env.Beta * omega)
+	}
+	return tempCrit.OmegaIntegralCos(env, cs[0], cs[1], cs[2], integrand)
+}
@@ -0,0 +1,53 @@
+package tempLow
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempCrit"
+
+// The holon grand potential should give the entropy and x_1 as its
+// derivatives, and agree with the normal-state form at F0 = 0.
+func TestHolonGrandPotential(t *testing.T) {
+	env, err := lowDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.PointsPerSide = 32
+	env.Mu_h, env.F0, env.Beta = 0.3, 0.1, 10.0
+	grand := func(T, Mu_h float64) float64 {
+		oBeta, oMu_h := env.Beta, env.Mu_h
+		env.Beta, env.Mu_h = 1.0/T, Mu_h
+		omega, err := HolonGrandPotential(env)
+		if err != nil {
+			t.Fatal(err)
+		}
+		env.Beta, env.Mu_h = oBeta, oMu_h
+		return omega
+	}
+	h := 1e-5
+	T := 1.0 / env.Beta
+	S, err := HolonEntropy(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SFromOmega := -(grand(T+h, env.Mu_h) - grand(T-h, env.Mu_h)) / (2.0 * h)
+	if math.Abs(S-SFromOmega) > 1e-7 {
+		t.Fatalf("entropy %v differs from -dOmega/dT = %v", S, SFromOmega)
+	}
+	x1FromOmega := -(grand(T, env.Mu_h+h) - grand(T, env.Mu_h-h)) / (2.0 * h)
+	if math.Abs(X1(env)-x1FromOmega) > 1e-7 {
+		t.Fatalf("x1 %v differs from -dOmega/dMu_h = %v", X1(env), x1FromOmega)
+	}
+	env.F0 = 0.0
+	low, _ := HolonGrandPotential(env)
+	crit, _ := tempCrit.HolonGrandPotential(env)
+	if math.Abs(low-crit) > 1e-12 {
+		t.Fatalf("F0 = 0 grand potential %v differs from normal state value %v", low, crit)
+	}
+	lowS, _ := HolonEntropy(env)
+	critS, _ := tempCrit.HolonEntropy(env)
+	if math.Abs(lowS-critS) > 1e-12 {
+		t.Fatalf("F0 = 0 entropy %v differs from normal state value %v", lowS, critS)
+	}
+}
@@ -47,13 +47,13 @@ func omegaFitHelper(env *tempAll.Environment, fn tempCrit.OmegaFunc, points []ve
 	}
 	// difference between fit and omega_i
 	errFuncF := func(cs vec.Vector, i int) (float64, error) {
-		return omegas[i] - OmegaFromFit(cs, points[i]), nil
+		return omegas[i] - omegaFromFitEnv(env, cs, points[i]), nil
 	}
 	errFuncDf := func(cs vec.Vector, i int) (vec.Vector, error) {
-		omega := OmegaFromFit(cs, points[i])
+		omega := omegaFromFitEnv(env, cs, points[i])
 		qx2 := math.Pow(points[i][0], 2.0)
 		qy2 := math.Pow(points[i][1], 2.0)
-		qz2 := math.Pow(points[i][2], 2.0)
+		qz2 := kzTerm(env, points[i][2])
 		left := (cs[0]*(qx2 + qy2) + cs[1]*qz2 - cs[2])
 		right := (cs[3]*(qx2 + qy2) + cs[4]*qz2 - cs[5])
 		result := make([]float64, 6)
@@ -73,14 +73,74 @@ func omegaFitHelper(env *tempAll.Environment, fn tempCrit.OmegaFunc, points []ve
 	return coeffs, nil
 }
 
-// Calculate omega(q) given the fit cs.
+// Calculate omega(q) given the fit cs, using the kz^2 form of the pair
+// spectrum.
 func OmegaFromFit(cs vec.Vector, q vec.Vector) float64 {
 	qx2 := math.Pow(q[0], 2.0)
 	qy2 := math.Pow(q[1], 2.0)
 	qz2 := math.Pow(q[2], 2.0)
-	left := (cs[0]*(qx2 + qy2) + cs[1]*qz2 - cs[2])
-	right := (cs[3]*(qx2 + qy2) + cs[4]*qz2 - cs[5])
-	return math.Pow(left*left - right*right, 0.5)
+	return omegaFromParts(cs, qx2+qy2, qz2)
+}
+
+// Calculate omega(q) given the fit cs, using the cos(kz) form of the pair
+// spectrum: kz^2 is replaced by 2(1 - cos(kz)).
+func OmegaFromFitCos(cs vec.Vector, q vec.Vector) float64 {
+	qx2 := math.Pow(q[0], 2.0)
+	qy2 := math.Pow(q[1], 2.0)
+	return omegaFromParts(cs, qx2+qy2, 2.0*(1.0-math.Cos(q[2])))
+}
+
+// Calculate omega(q) given the fit cs, using the form of the pair spectrum
+// selected by env.
+func omegaFromFitEnv(env *tempAll.Environment, cs vec.Vector, q vec.Vector) float64 {
+	if env.PairKzSquaredSpectrum {
+		return OmegaFromFit(cs, q)
+	}
+	return OmegaFromFitCos(cs, q)
+}
+
+// omega = sqrt(left^2 - right^2) with
+// left = cs[0]*q_perp^2 + cs[1]*zterm - cs[2],
+// right = cs[3]*q_perp^2 + cs[4]*zterm - cs[5].
+func omegaFromParts(cs vec.Vector, qperp2, zterm float64) float64 {
+	left := (cs[0]*qperp2 + cs[1]*zterm - cs[2])
+	right := (cs[3]*qperp2 + cs[4]*zterm - cs[5])
+	return math.Pow(left*left-right*right, 0.5)
+}
+
+// The kz dependence of the pair spectrum: kz^2 or 2(1 - cos(kz)).
+func kzTerm(env *tempAll.Environment, kz float64) float64 {
+	if env.PairKzSquaredSpectrum {
+		return math.Pow(kz, 2.0)
+	}
+	return 2.0 * (1.0 - math.Cos(kz))
+}
+
+// Calculate omega_+(k) below T_c; for use with OmegaFit.
+func OmegaPlus(env *tempAll.Environment, k vec.Vector) (float64, error) {
+	return OmegaPair(env, k, 1, 1)
+}
+
+// Anomalous pair gap Delta_p below T_c: the anomalous part of the pair
+// propagator at q = 0, M^{D,A}(0, omega -> 0), divided by the slope
+// -d Re M^D(0, omega)/d omega of its normal part. Both are taken along the
+// branch on which the pairs condense, the lower eigenvalue u - |v| of
+// Re M^D. Delta_p vanishes as F0^2 at T_c.
+func PairGap(env *tempAll.Environment) (float64, error) {
+	zero := []float64{0.0, 0.0, 0.0}
+	h := 1e-4
+	uPlus, vPlus := parts_MDiag(env, zero, h)
+	uMinus, vMinus := parts_MDiag(env, zero, -h)
+	sign := 1.0
+	if vPlus+vMinus < 0.0 {
+		sign = -1.0
+	}
+	slope := -((uPlus - sign*vPlus) - (uMinus - sign*vMinus)) / (2.0 * h)
+	if slope <= 0.0 || math.IsNaN(slope) {
+		return 0.0, fmt.Errorf("pair propagator has slope %v at q = 0; cannot find pair gap", slope)
+	}
+	uA, vA := parts_MDiagAnom(env, zero, h)
+	return math.Abs(uA-sign*vA) / slope, nil
 }
 
 // Returns a function of omega which evaluates lambda_{r, s}(k, omega),
@@ -0,0 +1,16 @@
+package tempLow
+
+import (
+	"math"
+	"testing"
+)
+
+// Near q = 0 the cos(kz) and kz^2 forms of the fitted spectrum should agree.
+func TestOmegaFromFitCosSmallQ(t *testing.T) {
+	cs := []float64{1.0, 0.1, -0.01, 0.5, 0.05, -0.005}
+	q := []float64{1e-3, 2e-3, 3e-3}
+	quad, cos := OmegaFromFit(cs, q), OmegaFromFitCos(cs, q)
+	if math.Abs(quad-cos) > 1e-10 {
+		t.Fatalf("cos(kz) spectrum %v differs from kz^2 spectrum %v at small q", cos, quad)
+	}
+}
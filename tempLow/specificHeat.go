@@ -1,13 +1,10 @@
 package tempLow
 
-import (
-	//"fmt"
-	"math"
-)
 import (
 	"github.com/tflovorn/scExplorer/solve"
 	"github.com/tflovorn/scExplorer/tempAll"
 	"github.com/tflovorn/scExplorer/tempCrit"
+	vec "github.com/tflovorn/scExplorer/vector"
 )
 
 type SpecificHeatEnv struct {
@@ -23,24 +20,52 @@ func GetSHTemp(d interface{}) float64 {
 
 type envFunc func(*tempAll.Environment) (float64, error)
 
-// Specific heat at constant volume due to particles with energy U
+// Specific heat at constant volume due to particles with energy U,
+// C = (dU/dMu_h)_T (dMu_h/dT)_x + (dU/dT)_{Mu_h}. The derivatives of the
+// solved variables are found from the Jacobian of the converged equations
+// (implicit function theorem) instead of by re-solving at shifted T and Mu_h.
 func specificHeat(env *tempAll.Environment, U envFunc) (float64, error) {
-	// calculate first derivatives
-	MuT, err := dMu_hdT(env)
+	v := []float64{env.D1, env.Mu_h, env.F0, env.X, env.Beta}
+	saved := make([]float64, len(v))
+	copy(saved, v)
+	// evaluating derivatives moves env away from the solution
+	defer env.Set(saved, shVariables)
+	jacobian, err := shSystem(env).Df(v)
+	if err != nil {
+		return 0.0, err
+	}
+	rows := []int{0, 1, 2}
+	// (D1, Mu_h, F0) vary with T at fixed x, as in D1MuF0Solve
+	dBeta, err := solve.ImplicitDerivatives(jacobian, rows, []int{shD1, shMu_h, shF0}, []int{shBeta})
 	if err != nil {
 		return 0.0, err
 	}
-	//fmt.Println(MuT)
-	UMu, err := dFdMu_h(env, U)
+	MuT := -env.Beta * env.Beta * dBeta[0][1]
+	// (D1, F0, x) vary with Mu_h and T, as in D1F0XSolve
+	unknowns := []int{shD1, shF0, shX}
+	dMuBeta, err := solve.ImplicitDerivatives(jacobian, rows, unknowns, []int{shMu_h, shBeta})
 	if err != nil {
 		return 0.0, err
 	}
-	//fmt.Println(UMu)
-	UT, err := dFdT(env, U)
+	UFn := func(v vec.Vector) (float64, error) {
+		env.Set(v, shVariables)
+		return U(env)
+	}
+	h, epsAbs := 1e-4, 1e-5
+	grad, err := solve.Gradient(UFn, v, h, epsAbs)
 	if err != nil {
 		return 0.0, err
 	}
-	//fmt.Println(UT)
+	// total derivatives of U with respect to Mu_h and Beta
+	total := func(j, param int) float64 {
+		d := grad[param]
+		for i, c := range unknowns {
+			d += grad[c] * dMuBeta[j][i]
+		}
+		return d
+	}
+	UMu := total(0, shMu_h)
+	UT := -env.Beta * env.Beta * total(1, shBeta)
 	return UMu*MuT + UT, nil
 }
 
@@ -51,96 +76,42 @@ func HolonSpecificHeat(env *tempAll.Environment) (float64, error) {
 
 // Specific heat at constant volume due to pairs
 func PairSpecificHeat(env *tempAll.Environment) (float64, error) {
-	return specificHeat(env, tempCrit.PairEnergy)
+	return specificHeat(env, PairEnergy)
 }
 
-// Partial derivative of Mu_h with respect to T; x and V held constant.
-func dMu_hdT(env *tempAll.Environment) (float64, error) {
-	ct := 0
-	// F gets Mu_h given Beta
-	F := func(Beta float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oMu_h, oBeta, oMu_b := env.D1, env.Mu_h, env.Beta, env.Mu_b
-		env.Beta = Beta
-		// fix free variables
-		eps := 1e-9
-		_, err := D1MuF0Solve(env, eps, eps)
-		if err != nil {
-			return 0.0, err
-		}
-		Mu_h := env.Mu_h
-		// restore the environment
-		env.D1, env.Mu_h, env.Beta, env.Mu_b = oD1, oMu_h, oBeta, oMu_b
-		return Mu_h, nil
-	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(F, env.Beta, h, epsAbs)
-	//fmt.Println("MuT ct", ct)
-	return -math.Pow(env.Beta, 2.0) * deriv, err
-}
+// Variables of shSystem, with their indices.
+var shVariables = []string{"D1", "Mu_h", "F0", "X", "Beta"}
 
-// Partial derivative of F (some function of env) with respect to Mu_h;
-// T and V held constant.
-func dFdMu_h(env *tempAll.Environment, F envFunc) (float64, error) {
-	ct := 0
-	// G gets F given Mu_h (allow x to vary; constant Beta)
-	G := func(Mu_h float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oMu_h, oX, oMu_b := env.D1, env.Mu_h, env.X, env.Mu_b
-		env.Mu_h = Mu_h
-		// fix free variables2
-		eps := 1e-9
-		_, err := D1F0XSolve(env, eps, eps)
-		if err != nil {
-			return 0.0, err
-		}
-		vF, err := F(env)
-		if err != nil {
-			return 0.0, err
-		}
-		// restore the environment
-		env.D1, env.Mu_h, env.X, env.Mu_b = oD1, oMu_h, oX, oMu_b
-		return vF, nil
-	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(G, env.Mu_h, h, epsAbs)
-	//fmt.Println("dF_dMu ct", ct)
-	return deriv, err
-}
+const (
+	shD1 = iota
+	shMu_h
+	shF0
+	shX
+	shBeta
+)
 
-// Partial derivative of F with respect to T; Mu_h and V held constant.
-func dFdT(env *tempAll.Environment, F envFunc) (float64, error) {
-	ct := 0
-	// G gets F given Beta (allow x to vary; constant Mu_h)
-	G := func(Beta float64) (float64, error) {
-		ct += 1
-		// save the environment state before changing it
-		// (don't want one call of F to affect the next)
-		oD1, oBeta, oX, oMu_b := env.D1, env.Beta, env.X, env.Mu_b
-		env.Beta = Beta
-		// fix free variables
-		eps := 1e-9
-		_, err := D1F0XSolve(env, eps, eps)
+// Equations of the T < T_c system as functions of shVariables, in the order:
+// D1, Mu_h (pair pole; the same equation as AbsErrorX), and
+// x = x_1 + x_2 (as in AbsErrorF0). None of these re-solve for other
+// variables when evaluated.
+func shSystem(env *tempAll.Environment) solve.DiffSystem {
+	F := func(v vec.Vector) (float64, error) {
+		env.Set(v, shVariables)
+		// pairs are condensed at the bottom of their spectrum
+		err := pinMu_b(env)
 		if err != nil {
 			return 0.0, err
 		}
-		vF, err := F(env)
+		x2, err := X2(env)
 		if err != nil {
 			return 0.0, err
 		}
-		// restore the environment
-		env.D1, env.Beta, env.X, env.Mu_b = oD1, oBeta, oX, oMu_b
-		return vF, nil
+		return env.X - X1(env) - x2, nil
 	}
-	h := 1e-4
-	epsAbs := 1e-5
-	deriv, err := solve.OneDimDerivative(G, env.Beta, h, epsAbs)
-	//fmt.Println("dF_dT ct", ct)
-	return -math.Pow(env.Beta, 2.0) * deriv, err
+	h := 1e-5
+	epsabs := 1e-4
+	diffX := solve.SimpleDiffable(F, len(shVariables), h, epsabs)
+	diffD1 := AbsErrorD1(env, shVariables)
+	diffMu_h := AbsErrorMu_h(env, shVariables)
+	return solve.Combine([]solve.Diffable{diffD1, diffMu_h, diffX})
 }
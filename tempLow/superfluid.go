@@ -0,0 +1,97 @@
+package tempLow
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Superfluid stiffness rho_s = rho_qp + rho_pair in the x direction, in units
+// of T0 (with e = hbar = a = 1). The inverse square of the London
+// penetration depth is proportional to rho_s; see units.PenetrationDepth.
+func SuperfluidStiffness(env *tempAll.Environment) (float64, error) {
+	pair, err := PairStiffness(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return QuasiparticleStiffness(env, 0) + pair, nil
+}
+
+// Stiffness from the Bogolyubov quasiparticles in direction dir (0 for x, 1
+// for y): the diamagnetic term minus the paramagnetic term,
+// rho_qp = <d^2xi/dk^2 (1 - xi/E tanh(beta E/2))/2 + (dxi/dk)^2 df/dE>_k.
+// Holons are spinless, so the occupation is n_k/2 (see HolonOccupation).
+// Vanishes (up to the grid resolution) when F0 = 0.
+func QuasiparticleStiffness(env *tempAll.Environment, dir int) float64 {
+	inner := func(env *tempAll.Environment, k vec.Vector) float64 {
+		d1, d2 := tempAll.XiDerivatives(env, k, dir)
+		return d2*env.HolonOccupation(k)/2.0 + d1*d1*fermiDeriv(env, env.BogoEnergy(k))
+	}
+	return bzone.Avg(env.PointsPerSide, env.HolonDim(), tempAll.WrapFunc(env, inner))
+}
+
+// Stiffness of the condensed pairs, which carry charge 2 and have mass
+// 1/(2 a_x) from the pair spectrum omega = a_x q_x^2 + ...; there are
+// x_c/2 condensed pairs per site (x_c = CondensateX), so
+// rho_pair = 4 a_x x_c.
+func PairStiffness(env *tempAll.Environment) (float64, error) {
+	if env.F0 == 0.0 {
+		return 0.0, nil
+	}
+	ax, _, _, err := tempCrit.PairCoeffs(env)
+	if err != nil {
+		return 0.0, fmt.Errorf("cannot find pair spectrum for stiffness: %v", err)
+	}
+	return 4.0 * ax * CondensateX(env), nil
+}
+
+// Fit rho0 - rho_s(T) = c T^n to the low-temperature stiffness values rhos at
+// temperatures Ts. Returns (n, c). For a d-wave gap with nodes n -> 1; for a
+// nodeless gap the deficit is exponentially small and n is large.
+func StiffnessPowerLaw(Ts, rhos []float64, rho0 float64) (float64, float64, error) {
+	if len(Ts) != len(rhos) {
+		return 0.0, 0.0, fmt.Errorf("got %d temperatures but %d stiffness values", len(Ts), len(rhos))
+	}
+	// least squares fit of log(rho0 - rho) = log(c) + n log(T)
+	var xs, ys []float64
+	for i, T := range Ts {
+		deficit := rho0 - rhos[i]
+		if T > 0.0 && deficit > 0.0 {
+			xs = append(xs, math.Log(T))
+			ys = append(ys, math.Log(deficit))
+		}
+	}
+	if len(xs) < 2 {
+		return 0.0, 0.0, fmt.Errorf("need at least 2 points with T > 0 and rho_s < rho0 for power law fit")
+	}
+	N := float64(len(xs))
+	mx, my := 0.0, 0.0
+	for i := range xs {
+		mx += xs[i] / N
+		my += ys[i] / N
+	}
+	sxy, sxx := 0.0, 0.0
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if sxx == 0.0 {
+		return 0.0, 0.0, fmt.Errorf("power law fit needs at least 2 distinct temperatures")
+	}
+	n := sxy / sxx
+	return n, math.Exp(my - n*mx), nil
+}
+
+// df/dE for the Fermi function at env.Beta; zero at T = 0 for E != 0.
+func fermiDeriv(env *tempAll.Environment, E float64) float64 {
+	if math.IsInf(env.Beta, 1) {
+		return 0.0
+	}
+	f := env.Fermi(E)
+	return -env.Beta * f * (1.0 - f)
+}
@@ -0,0 +1,55 @@
+package tempLow
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// The quasiparticle stiffness should vanish in the normal state, and the fit
+// should recover an exact power law.
+func TestStiffnessNormalState(t *testing.T) {
+	env, err := lowDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.F0 = 0.0
+	env.PointsPerSide = 128
+	if rho := QuasiparticleStiffness(env, 0); math.Abs(rho) > 1e-6 {
+		t.Fatalf("normal-state quasiparticle stiffness %v is nonzero", rho)
+	}
+	Ts := []float64{0.01, 0.02, 0.04}
+	rhos := []float64{1.0 - 3.0*0.01, 1.0 - 3.0*0.02, 1.0 - 3.0*0.04}
+	n, c, err := StiffnessPowerLaw(Ts, rhos, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(n-1.0) > 1e-9 || math.Abs(c-3.0) > 1e-9 {
+		t.Fatalf("power law fit gave n = %v, c = %v; expected 1, 3", n, c)
+	}
+}
+
+// At T = 0 and low density the quasiparticle stiffness should be the
+// London value n/m, with n the holon density per site and 1/m the band
+// curvature along x at the band minimum k = 0, whatever the gap.
+func TestStiffnessLowDensity(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 512, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.0, "Mu_h": 0.02, "F0": 0.001}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Beta = math.Inf(1)
+	n := bzone.Avg(env.PointsPerSide, 2, func(k vec.Vector) float64 {
+		return env.HolonOccupation(k) / 2.0
+	})
+	_, invMass := tempAll.XiDerivatives(env, vec.Vector{0.0, 0.0}, 0)
+	expected := n * invMass
+	rho := QuasiparticleStiffness(env, 0)
+	t.Logf("n = %v, 1/m = %v, rho_qp = %v", n, invMass, rho)
+	if math.Abs(rho-expected) > 0.05*expected {
+		t.Fatalf("T = 0 quasiparticle stiffness %v; expected n/m = %v", rho, expected)
+	}
+}
@@ -54,8 +54,9 @@ func D1F0XSystem(env *tempAll.Environment) (solve.DiffSystem, []float64) {
 func D1MuBetaSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector, error) {
 	// our guess for beta should be above beta_c
 	if env.A == 0.0 && env.B == 0.0 {
-		D1, Mu_h, F0 := env.D1, env.Mu_h, env.F0
-		env.F0 = 0.0 // F0 is 0 at T_c
+		D1, Mu_h, F0, Be_field := env.D1, env.Mu_h, env.F0, env.Be_field
+		env.F0 = 0.0       // F0 is 0 at T_c
+		env.Be_field = 0.0 // pair spectrum is fixed at the zero-field T_c
 		_, err := tempCrit.CritTempSolve(env, epsAbs, epsRel)
 		if err != nil {
 			return nil, err
@@ -65,11 +66,13 @@ func D1MuBetaSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector
 		if err != nil {
 			return nil, err
 		}
-		env.A, env.B = omegaFit[0], omegaFit[2]
-		env.PairCoeffsReady = true
+		err = tempCrit.FixPairSpectrum(env, omegaFit)
+		if err != nil {
+			return nil, err
+		}
 		env.Beta += 0.1
 		// we are at T < T_c; uncache env
-		env.D1, env.Mu_h, env.F0 = D1, Mu_h, F0
+		env.D1, env.Mu_h, env.F0, env.Be_field = D1, Mu_h, F0, Be_field
 	}
 	//fmt.Printf("%v; Tc = %f\n", env, 1.0 / env.Beta)
 	// solve low temp system for reasonable values of D1 and Mu_h first
@@ -92,7 +95,9 @@ func D1MuF0Solve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector,
 		// We must have T < T_c < T_p (Beta > Beta_c > Beta_p).
 		// Getting Beta_p is fast, so do that first.
 		D1, Mu_h, F0, Beta := env.F0, env.Mu_h, env.F0, env.Beta // cache env
-		env.F0 = 0.0                                             // F0 is 0 at T_c and T_p
+		Be_field := env.Be_field
+		env.F0 = 0.0       // F0 is 0 at T_c and T_p
+		env.Be_field = 0.0 // pair spectrum is fixed at the zero-field T_c
 		_, err := tempPair.PairTempSolve(env, epsAbs, epsRel)
 		if err != nil {
 			return nil, err
@@ -112,10 +117,13 @@ func D1MuF0Solve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector,
 		if err != nil {
 			return nil, err
 		}
-		env.A, env.B = omegaFit[0], omegaFit[2]
-		env.PairCoeffsReady = true
+		err = tempCrit.FixPairSpectrum(env, omegaFit)
+		if err != nil {
+			return nil, err
+		}
 		// we are at T < T_c; uncache env
 		env.D1, env.Mu_h, env.F0, env.Beta = D1, Mu_h, F0, Beta
+		env.Be_field = Be_field
 	}
 	// solve low temp system for reasonable values of D1 and Mu_h first
 	_, err := D1MuSolve(env, epsAbs, epsRel)
@@ -17,7 +17,7 @@ func AbsErrorD1(env *tempAll.Environment, variables []string) solve.Diffable {
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := env.D1
-		rhs := -bzone.Avg(L, 2, tempAll.WrapFunc(env, innerD1)) / 2.0
+		rhs := -bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerD1)) / 2.0
 		return lhs - rhs, nil
 	}
 	h := 1e-5
@@ -41,7 +41,6 @@ import (
 import (
 	"github.com/tflovorn/scExplorer/solve"
 	"github.com/tflovorn/scExplorer/tempAll"
-	"github.com/tflovorn/scExplorer/tempCrit"
 	vec "github.com/tflovorn/scExplorer/vector"
 )
 
@@ -61,9 +60,14 @@ func AbsErrorF0(env *tempAll.Environment, variables []string) solve.Diffable {
 				return 0.0, err
 			}
 		}
+		// pairs are condensed at the bottom of their spectrum
+		err := pinMu_b(env)
+		if err != nil {
+			return 0.0, err
+		}
 		// F0 equation error = x - x1 - x2
 		x1 := X1(env)
-		x2, err := tempCrit.X2(env)
+		x2, err := X2(env)
 		if err != nil {
 			fmt.Printf("error from X2(): %v\n", err)
 			return 0.0, err
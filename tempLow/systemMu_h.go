@@ -15,7 +15,7 @@ func AbsErrorMu_h(env *tempAll.Environment, variables []string) solve.Diffable {
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := 2.0 / (env.T0 + env.Tz)
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerMu_h))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerMu_h))
 		return lhs - rhs, nil
 	}
 	h := 1e-5
@@ -24,9 +24,8 @@ func AbsErrorMu_h(env *tempAll.Environment, variables []string) solve.Diffable {
 }
 
 func innerMu_h(env *tempAll.Environment, k vec.Vector) float64 {
-	sxy := math.Sin(k[0]) - math.Sin(k[1])
 	E := env.BogoEnergy(k)
 	xi := env.Xi_h(k)
-	delta := env.Delta_h(k)
-	return sxy * sxy * math.Tanh(env.Beta*E/2.0) * (2.0*xi*xi + delta*delta) / (E * E * E)
+	delta2 := env.DeltaAbs2_h(k)
+	return env.GapWeight(k) * math.Tanh(env.Beta*E/2.0) * (2.0*xi*xi + delta2) / (E * E * E)
 }
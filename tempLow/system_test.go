@@ -12,7 +12,6 @@ import (
 	"github.com/tflovorn/scExplorer/parallel"
 	"github.com/tflovorn/scExplorer/plots"
 	"github.com/tflovorn/scExplorer/tempAll"
-	"github.com/tflovorn/scExplorer/tempCrit"
 )
 
 var testPlot = flag.Bool("testPlot", false, "Run tests involving plots")
@@ -119,7 +118,7 @@ func TestPlotX2VsT(t *testing.T) {
 	graphParams[plots.YLABEL_KEY] = "$x_2$"
 	vars.X = ""
 	vars.XFunc = tempAll.GetTemp
-	vars.YFunc = tempCrit.GetX2
+	vars.YFunc = GetX2
 	err = plots.MultiPlot(plotEnvs, errs, vars, graphParams, grapherPath)
 	if err != nil {
 		t.Fatalf("error making X2(T) plot: %v", err)
@@ -143,7 +142,7 @@ func TestPlotX2VsT(t *testing.T) {
 		vars.X = "Be_field"
 		vars.XFunc = nil
 		vars.Y = ""
-		vars.YFunc = tempCrit.GetMagnetization
+		vars.YFunc = GetMagnetization
 		vars.Params = []string{"Tz", "Thp", "X", "F0"}
 		vars.ParamLabels = []string{"t_z", "t_h^{\\prime}", "x", "F_0"}
 		err := plots.MultiPlot(plotEnvs, errs, vars, graphParams, grapherPath)
@@ -168,7 +167,7 @@ func TestPlotX2VsT(t *testing.T) {
 		if !ok {
 			cerr <- errors.New("pe is not Environment")
 		}
-		X2, err := tempCrit.X2(&env)
+		X2, err := X2(&env)
 		if err != nil {
 			cerr <- err
 			return
@@ -10,7 +10,7 @@ import (
 // Concentration of unpaired holons
 func X1(env *tempAll.Environment) float64 {
 	L := env.PointsPerSide
-	x1 := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerX1)) / 2.0
+	x1 := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerX1)) / 2.0
 	return x1
 }
 
@@ -0,0 +1,102 @@
+package tempLow
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Concentration of holons in uncondensed pairs below T_c.
+//
+// With the pair spectrum fixed at T_c, at F0 = 0, at finite field, with the
+// kz^2 spectrum, or with NoBogoliubovPairs set this is the same as
+// tempCrit.X2. Otherwise the pairs have the Bogoliubov spectrum
+// omega = sqrt(left^2 - right^2) of bogoliubovOmega, built on the cos(kz)
+// form of the T >= T_c spectrum, so that x2 is continuous at T_c.
+func X2(env *tempAll.Environment) (float64, error) {
+	cs, ok, err := bogoliubovCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	if !ok {
+		return tempCrit.X2(env)
+	}
+	integrand := func(y, kz float64) float64 {
+		left, omega := bogoliubovOmega(env, cs, y, kz)
+		nb := 1.0 / (math.Exp(env.Beta*omega) - 1.0)
+		return 2.0 * (left/omega*(nb+0.5) - 0.5)
+	}
+	return tempCrit.OmegaIntegralCos(env, cs[0], cs[1], cs[2], integrand)
+}
+
+// Energy of uncondensed pairs below T_c,
+// U_2/N = 1/N \sum_k [\omega(k) n_b(\omega(k)) + (\omega(k) - left(k))/2].
+// The zero-point term is the energy of the quantum depletion counted in X2;
+// with it, x_2/2 = -\partial(\Omega_2/N)/\partial\mu_b for the Omega_2 of
+// PairGrandPotential. Falls back to tempCrit.PairEnergy in the same cases as
+// X2.
+func PairEnergy(env *tempAll.Environment) (float64, error) {
+	cs, ok, err := bogoliubovCoeffs(env)
+	if err != nil {
+		return 0.0, err
+	}
+	if !ok {
+		return tempCrit.PairEnergy(env)
+	}
+	integrand := func(y, kz float64) float64 {
+		left, omega := bogoliubovOmega(env, cs, y, kz)
+		return omega/(math.Exp(env.Beta*omega)-1.0) + (omega-left)/2.0
+	}
+	return tempCrit.OmegaIntegralCos(env, cs[0], cs[1], cs[2], integrand)
+}
+
+// Equivalent to X2(); for use as YFunc in a plots.GraphVars
+func GetX2(data interface{}) float64 {
+	env := data.(tempAll.Environment)
+	X2, err := X2(&env)
+	if err != nil {
+		panic(err)
+	}
+	return X2
+}
+
+// Return the Bogoliubov pair spectrum coefficients {ax, ay, b, Delta_p} for
+// env, and whether they should be used instead of the T >= T_c spectrum.
+// (ax, ay, b) are the omega_+ coefficients at the current D1, Mu_h and Beta
+// with F0 = 0, and Delta_p is the anomalous pair gap of PairGap. The
+// Bogoliubov spectrum is not Landau quantized, so it is not used at finite
+// field (see field.go).
+func bogoliubovCoeffs(env *tempAll.Environment) (vec.Vector, bool, error) {
+	if env.F0 == 0.0 || env.PairKzSquaredSpectrum || env.Be_field != 0.0 || env.NoBogoliubovPairs {
+		return nil, false, nil
+	}
+	if env.FixedPairCoeffs && env.PairCoeffsReady {
+		return nil, false, nil
+	}
+	normalEnv := env.Copy()
+	normalEnv.F0 = 0.0
+	ax, ay, b, err := tempCrit.PairCoeffs(normalEnv)
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot find pair spectrum for Bogoliubov pairs: %v", err)
+	}
+	Delta_p, err := PairGap(env)
+	if err != nil {
+		return nil, false, err
+	}
+	return vec.Vector{ax, ay, b, Delta_p}, true, nil
+}
+
+// Return (left, omega) for the Bogoliubov pair spectrum with coefficients cs
+// at y = beta*a*q_perp^2 and kz. The pairs are condensed, so Mu_b is pinned
+// to the bottom of the spectrum and omega vanishes at q = 0:
+// with eps = y/beta + 2b(1 - cos(kz)), left = eps + Delta_p,
+// right = Delta_p and omega = sqrt(eps*(eps + 2 Delta_p)).
+func bogoliubovOmega(env *tempAll.Environment, cs vec.Vector, y, kz float64) (float64, float64) {
+	eps := y/env.Beta + 2.0*cs[2]*(1.0-math.Cos(kz))
+	Delta_p := cs[3]
+	return eps + Delta_p, math.Sqrt(eps * (eps + 2.0*Delta_p))
+}
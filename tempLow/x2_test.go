@@ -0,0 +1,53 @@
+package tempLow
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+)
+
+// With the Bogoliubov pair spectrum, x2 below T_c should approach its value
+// at T_c from tempCrit.X2 as F0 vanishes.
+func TestX2BogoliubovContinuity(t *testing.T) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "D1": 0.01, "Mu_h": -0.5, "Beta": 4.0, "F0": 0.0, "Mu_b": 0.0}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := 1e-6
+	_, err = tempCrit.CritTempSolve(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x2c, err := tempCrit.X2(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lastDiff := math.Inf(1)
+	for _, F0 := range []float64{1e-2, 1e-3, 1e-4} {
+		lowEnv := env.Copy()
+		lowEnv.F0 = F0
+		_, err = D1MuSolve(lowEnv, eps, eps)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := bogoliubovCoeffs(lowEnv); err != nil || !ok {
+			t.Fatalf("Bogoliubov spectrum not used at F0 = %v (%v)", F0, err)
+		}
+		x2, err := X2(lowEnv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		diff := math.Abs(x2 - x2c)
+		t.Logf("F0 = %v: x2 = %v; x2(T_c) = %v", F0, x2, x2c)
+		if diff >= lastDiff {
+			t.Fatalf("x2 = %v at F0 = %v does not approach x2(T_c) = %v", x2, F0, x2c)
+		}
+		lastDiff = diff
+	}
+	if lastDiff > 1e-2*x2c {
+		t.Fatalf("x2 differs from x2(T_c) = %v by %v at small F0", x2c, lastDiff)
+	}
+}
@@ -6,13 +6,22 @@ import (
 	vec "github.com/tflovorn/scExplorer/vector"
 )
 
+// For a mixed gap (s+d or d+is), the mixing F1 at T_p is also solved for.
 func PairTempSystem(env *tempAll.Environment) (solve.DiffSystem, []float64) {
 	variables := []string{"D1", "Mu_h", "Beta"}
+	start := []float64{env.D1, env.Mu_h, env.Beta}
+	if env.MixedGap() {
+		variables = append(variables, "F1")
+		start = append(start, env.F1)
+	}
 	diffD1 := AbsErrorD1(env, variables)
 	diffMu_h := AbsErrorMu_h(env, variables)
 	diffBeta := AbsErrorBeta(env, variables)
-	system := solve.Combine([]solve.Diffable{diffD1, diffMu_h, diffBeta})
-	start := []float64{env.D1, env.Mu_h, env.Beta}
+	diffs := []solve.Diffable{diffD1, diffMu_h, diffBeta}
+	if env.MixedGap() {
+		diffs = append(diffs, AbsErrorF1(env, variables))
+	}
+	system := solve.Combine(diffs)
 	return system, start
 }
 
@@ -24,3 +33,24 @@ func PairTempSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector
 	}
 	return solution, nil
 }
+
+// For use with solve.MultiDim: (D1, Mu_h) system with Beta and x fixed.
+// Appropriate for T > T_p, where there are no pairs.
+func D1MuSystem(env *tempAll.Environment) (solve.DiffSystem, []float64) {
+	variables := []string{"D1", "Mu_h"}
+	diffD1 := AbsErrorD1(env, variables)
+	diffMu_h := AbsErrorMu_h(env, variables)
+	system := solve.Combine([]solve.Diffable{diffD1, diffMu_h})
+	start := []float64{env.D1, env.Mu_h}
+	return system, start
+}
+
+// Solve the (D1, Mu_h) system with Beta and x fixed.
+func D1MuSolve(env *tempAll.Environment, epsAbs, epsRel float64) (vec.Vector, error) {
+	system, start := D1MuSystem(env)
+	solution, err := solve.MultiDim(system, start, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	return solution, nil
+}
@@ -2,19 +2,28 @@ package tempPair
 
 import "math"
 import (
-	"github.com/tflovorn/scExplorer/bzone"
 	"github.com/tflovorn/scExplorer/solve"
 	"github.com/tflovorn/scExplorer/tempAll"
 	vec "github.com/tflovorn/scExplorer/vector"
 )
 
 func AbsErrorBeta(env *tempAll.Environment, variables []string) solve.Diffable {
+	return absErrorGap(env, variables, 0)
+}
+
+// Return the absolute error and gradient of the linearized gap equation for
+// the second component of a mixed gap, which determines the mixing F1 at
+// the pairing temperature.
+func AbsErrorF1(env *tempAll.Environment, variables []string) solve.Diffable {
+	return absErrorGap(env, variables, 1)
+}
+
+// Error in the i'th of the coupled linearized gap equations
+// (see tempAll.Environment.GapEquationErrors).
+func absErrorGap(env *tempAll.Environment, variables []string, i int) solve.Diffable {
 	F := func(v vec.Vector) (float64, error) {
 		env.Set(v, variables)
-		L := env.PointsPerSide
-		lhs := 1.0 / (env.T0 + env.Tz)
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerBeta))
-		return lhs - rhs, nil
+		return env.GapEquationErrors(tempAll.WrapFunc(env, innerBeta))[i], nil
 	}
 	h := 1e-4
 	epsabs := 1e-9
@@ -22,6 +31,5 @@ func AbsErrorBeta(env *tempAll.Environment, variables []string) solve.Diffable {
 }
 
 func innerBeta(env *tempAll.Environment, k vec.Vector) float64 {
-	sxy := math.Sin(k[0]) - math.Sin(k[1])
-	return sxy * sxy * math.Tanh(env.Beta*env.Xi_h(k)/2.0) / env.Xi_h(k)
+	return math.Tanh(env.Beta*env.Xi_h(k)/2.0) / env.Xi_h(k)
 }
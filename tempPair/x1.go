@@ -9,7 +9,7 @@ import (
 // Concentration of unpaired holons
 func X1(env *tempAll.Environment) float64 {
 	L := env.PointsPerSide
-	return bzone.Avg(L, 2, tempAll.WrapFunc(env, innerX1))
+	return bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerX1))
 }
 
 func innerX1(env *tempAll.Environment, k vec.Vector) float64 {
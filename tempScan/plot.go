@@ -0,0 +1,100 @@
+package tempScan
+
+import "reflect"
+import (
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/units"
+)
+
+// Plot the scan variable y (a field of ScanEnv, such as "Mu_h" or "X2")
+// against T, labelled with the LaTeX symbol sym. If the scanned Environment
+// has a physical energy scale (see units.HasPhysicalScale), T is given in K
+// and, if energy is true, y is given in meV; otherwise both are relative to
+// t_0. params[plots.FILE_KEY] gives the output path prefix; axis labels are
+// set unless params already gives them.
+func (result *ScanResult) Plot(y, sym string, energy bool, params map[string]string, grapherPath string) error {
+	var scaleEnv *tempAll.Environment
+	for i, d := range result.Envs {
+		if result.Errs[i] == nil {
+			env := d.(ScanEnv)
+			scaleEnv = &env.Environment
+			break
+		}
+	}
+	if scaleEnv == nil {
+		return nil
+	}
+	xFn := func(d interface{}) float64 {
+		env := d.(ScanEnv)
+		return physicalTemps(&env.Environment, []float64{env.Temp})[0]
+	}
+	yFn := func(d interface{}) float64 {
+		env := d.(ScanEnv)
+		v := reflect.ValueOf(env).FieldByName(y).Float()
+		if energy {
+			return physicalEnergies(&env.Environment, []float64{v})[0]
+		}
+		return v
+	}
+	yLabel := "$" + sym + "$"
+	if energy {
+		yLabel = units.EnergyLabel(scaleEnv, sym)
+	}
+	setLabels(params, units.TempLabel(scaleEnv, "T"), yLabel)
+	vars := plots.GraphVars{Params: []string{"X"}, ParamLabels: []string{"x"}, XFunc: xFn, YFunc: yFn}
+	return plots.MultiPlot(result.Envs, result.Errs, vars, params, grapherPath)
+}
+
+// Plot rho_s(T) as found by Stiffness, in physical units if env has a
+// physical energy scale.
+func PlotStiffness(env *tempAll.Environment, Ts, rhos []float64, params map[string]string, grapherPath string) error {
+	setLabels(params, units.TempLabel(env, "T"), units.EnergyLabel(env, "\\rho_s"))
+	series := plots.MakeSeries(physicalTemps(env, Ts), physicalEnergies(env, rhos))
+	return plots.PlotMPL([]plots.Series{series}, params, []map[string]string{{"style": "k-"}}, grapherPath)
+}
+
+// Plot T_c against rho_s(0) as found by Uemura, in physical units if env has
+// a physical energy scale.
+func PlotUemura(env *tempAll.Environment, Tcs, rho0s []float64, params map[string]string, grapherPath string) error {
+	setLabels(params, units.EnergyLabel(env, "\\rho_s(0)"), units.TempLabel(env, "T_c"))
+	series := plots.MakeSeries(physicalEnergies(env, rho0s), physicalTemps(env, Tcs))
+	return plots.PlotMPL([]plots.Series{series}, params, []map[string]string{{"style": "ko-"}}, grapherPath)
+}
+
+// Set the axis labels in params to xLabel and yLabel, unless params already
+// gives them.
+func setLabels(params map[string]string, xLabel, yLabel string) {
+	if _, ok := params[plots.XLABEL_KEY]; !ok {
+		params[plots.XLABEL_KEY] = xLabel
+	}
+	if _, ok := params[plots.YLABEL_KEY]; !ok {
+		params[plots.YLABEL_KEY] = yLabel
+	}
+}
+
+// Model temperatures Ts in K if env has a physical energy scale; otherwise
+// Ts unchanged.
+func physicalTemps(env *tempAll.Environment, Ts []float64) []float64 {
+	if !units.HasPhysicalScale(env) {
+		return Ts
+	}
+	ret := make([]float64, len(Ts))
+	for i, T := range Ts {
+		ret[i], _ = units.Temperature(env, T)
+	}
+	return ret
+}
+
+// Model energies Es in meV if env has a physical energy scale; otherwise Es
+// unchanged.
+func physicalEnergies(env *tempAll.Environment, Es []float64) []float64 {
+	if !units.HasPhysicalScale(env) {
+		return Es
+	}
+	ret := make([]float64, len(Es))
+	for i, E := range Es {
+		ret[i], _ = units.Energy(env, E)
+	}
+	return ret
+}
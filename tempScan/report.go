@@ -0,0 +1,117 @@
+package tempScan
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Characteristic energy scales at one doping. Gaps are those of the T = 0
+// solution. The Fermi surface quantities are found on the T = 0 Fermi
+// surface and are zero if it has none (HasFermiSurface = false).
+type GapReport struct {
+	X, Tc, Tp       float64
+	DeltaMax        float64 // max_k |Delta_h(k)|
+	QPGapMin        float64 // min_k E_k
+	GapRatio        float64 // 2 DeltaMax / T_c
+	TpTc            float64 // T_p / T_c
+	HasFermiSurface bool
+	Antinodal       float64 // max of |Delta_h| on the Fermi surface
+	NodalGap        float64 // min of |Delta_h| on the Fermi surface
+	NodalVF         float64 // Fermi velocity at the gap minimum on the Fermi surface
+	NodalVDelta     float64 // |d|Delta_h|/dk| along the Fermi surface at the gap minimum
+}
+
+func (r *GapReport) String() string {
+	s := fmt.Sprintf("x = %g: T_c = %g, T_p = %g, T_p/T_c = %g; Delta_max = %g, 2 Delta_max/T_c = %g, min E_k = %g", r.X, r.Tc, r.Tp, r.TpTc, r.DeltaMax, r.GapRatio, r.QPGapMin)
+	if r.HasFermiSurface {
+		s += fmt.Sprintf("; antinodal gap = %g, nodal gap = %g, v_F = %g, v_Delta = %g", r.Antinodal, r.NodalGap, r.NodalVF, r.NodalVDelta)
+	}
+	return s
+}
+
+// Number of points per side of the grid used to find the Fermi surface.
+const REPORT_FS_POINTS = 256
+
+// Report the energy scales of the solutions zeroEnv (T = 0), tcEnv (at T_c)
+// and tpEnv (at T_p), which must have the same X.
+func GapRatios(zeroEnv, tcEnv, tpEnv *tempAll.Environment) (*GapReport, error) {
+	if zeroEnv.X != tcEnv.X || zeroEnv.X != tpEnv.X {
+		return nil, fmt.Errorf("GapRatios requires equal X; got %v, %v and %v", zeroEnv.X, tcEnv.X, tpEnv.X)
+	}
+	r := &GapReport{X: zeroEnv.X, Tc: 1.0 / tcEnv.Beta, Tp: 1.0 / tpEnv.Beta}
+	r.TpTc = r.Tp / r.Tc
+	negGap := func(k vec.Vector) float64 {
+		return -math.Sqrt(zeroEnv.DeltaAbs2_h(k))
+	}
+	r.DeltaMax = -bzone.Min(zeroEnv.PointsPerSide, 2, negGap)
+	r.GapRatio = 2.0 * r.DeltaMax / r.Tc
+	r.QPGapMin = dos.GapEdge(zeroEnv)
+	// gap on the Fermi surface
+	lines := tempAll.FermiContours(zeroEnv, 0.0, REPORT_FS_POINTS)
+	if len(lines) == 0 {
+		return r, nil
+	}
+	r.HasFermiSurface = true
+	r.NodalGap = math.MaxFloat64
+	var node vec.Vector
+	for _, line := range lines {
+		for _, k := range line.Points {
+			gap := math.Sqrt(zeroEnv.DeltaAbs2_h(k))
+			if gap > r.Antinodal {
+				r.Antinodal = gap
+			}
+			if gap < r.NodalGap {
+				r.NodalGap, node = gap, k
+			}
+		}
+	}
+	vF := tempAll.FermiVelocity(zeroEnv, node)
+	r.NodalVF = math.Hypot(vF[0], vF[1])
+	// slope of the gap along the Fermi surface (perpendicular to v_F); use
+	// the signed gap, since |Delta_h| has a cusp at a node
+	if r.NodalVF > 0.0 {
+		h := 1e-5
+		tx, ty := -vF[1]/r.NodalVF, vF[0]/r.NodalVF
+		plus := vec.Vector{node[0] + h*tx, node[1] + h*ty}
+		minus := vec.Vector{node[0] - h*tx, node[1] - h*ty}
+		phase := cmplx.Conj(zeroEnv.GapPhase())
+		gapPlus, gapMinus := real(zeroEnv.DeltaComplex_h(plus)*phase), real(zeroEnv.DeltaComplex_h(minus)*phase)
+		r.NodalVDelta = math.Abs(gapPlus-gapMinus) / (2.0 * h)
+	}
+	return r, nil
+}
+
+// GapRatios at each doping in Xs, with the other parameters taken from env.
+// Dopings which could not be solved are skipped (with an error printed).
+func GapRatioSweep(env *tempAll.Environment, Xs []float64, epsAbs, epsRel float64) []*GapReport {
+	reports := []*GapReport{}
+	for _, X := range Xs {
+		xEnv := env.Copy()
+		xEnv.X = X
+		tpEnv, tcEnv, err := criticalEnvs(xEnv, epsAbs, epsRel)
+		if err != nil {
+			fmt.Printf("Error: %v; produced while solving gap ratios at x = %f\n", err, X)
+			continue
+		}
+		Tc, Tp := 1.0/tcEnv.Beta, 1.0/tpEnv.Beta
+		zeroEnv, err := solveAt(tcEnv, 0.0, Tc, Tp, epsAbs, epsRel)
+		if err != nil {
+			fmt.Printf("Error: %v; produced while solving T = 0 at x = %f\n", err, X)
+			continue
+		}
+		r, err := GapRatios(&zeroEnv.Environment, tcEnv, tpEnv)
+		if err != nil {
+			fmt.Printf("Error: %v; produced while finding gap ratios at x = %f\n", err, X)
+			continue
+		}
+		reports = append(reports, r)
+	}
+	return reports
+}
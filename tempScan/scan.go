@@ -0,0 +1,241 @@
+// Solutions over the full temperature range, from T = 0 through T_c and T_p.
+package tempScan
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+import (
+	"github.com/tflovorn/scExplorer/solve"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempFluc"
+	"github.com/tflovorn/scExplorer/tempLow"
+	"github.com/tflovorn/scExplorer/tempPair"
+	"github.com/tflovorn/scExplorer/tempZero"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Temperature regimes, each handled by a different solver.
+const (
+	ZeroTemp   = iota // T = 0 (tempZero)
+	LowTemp           // 0 < T < T_c (tempLow)
+	FlucTemp          // T_c <= T < T_p (tempFluc)
+	NormalTemp        // T >= T_p (no pairs)
+)
+
+// A solved Environment at one temperature of a scan.
+type ScanEnv struct {
+	tempAll.Environment
+	X2     float64 // concentration of uncondensed pairs
+	Regime int     // one of ZeroTemp, LowTemp, FlucTemp, NormalTemp
+}
+
+// Result of ThermalScan. Envs and Errs are in order of ascending
+// temperature. Envs contains ScanEnv values (as interface{} so they can be
+// passed directly to plots.MultiPlot); Envs[i] is nil if Errs[i] != nil.
+type ScanResult struct {
+	Tc, Tp float64
+	Temps  []float64
+	Envs   []interface{}
+	Errs   []error
+}
+
+// Get T for a ScanEnv
+func GetScanTemp(d interface{}) float64 {
+	env := d.(ScanEnv)
+	return env.Temp
+}
+
+// Solve env at each of the given temperatures, keeping X and the hopping
+// parameters of env fixed. T_p and T_c are found first; each temperature is
+// then solved with the solver for its regime. Temperatures are solved
+// outward from T_c, so that each solution starts from the previous one and
+// the solutions on either side of T_c start from the T_c solution.
+func ThermalScan(env *tempAll.Environment, temps []float64, epsAbs, epsRel float64) (*ScanResult, error) {
+	tpEnv, tcEnv, err := criticalEnvs(env, epsAbs, epsRel)
+	if err != nil {
+		return nil, err
+	}
+	Tp, Tc := 1.0/tpEnv.Beta, 1.0/tcEnv.Beta
+	sorted := make([]float64, len(temps))
+	copy(sorted, temps)
+	sort.Float64s(sorted)
+	result := &ScanResult{Tc, Tp, sorted, make([]interface{}, len(sorted)), make([]error, len(sorted))}
+	// index of the lowest temperature >= T_c
+	iTc := sort.SearchFloat64s(sorted, Tc)
+	// T < T_c: solve downward from T_c
+	last := tcEnv
+	for i := iTc - 1; i >= 0; i-- {
+		thisEnv, err := solveAt(last, sorted[i], Tc, Tp, epsAbs, epsRel)
+		result.record(i, thisEnv, err)
+		if err == nil {
+			last = &thisEnv.Environment
+		}
+	}
+	// T >= T_c: solve upward from T_c
+	last = tcEnv
+	for i := iTc; i < len(sorted); i++ {
+		thisEnv, err := solveAt(last, sorted[i], Tc, Tp, epsAbs, epsRel)
+		result.record(i, thisEnv, err)
+		if err == nil {
+			last = &thisEnv.Environment
+		}
+	}
+	return result, nil
+}
+
+// Solve for T_p and T_c at the X and hopping parameters of env. The T_c
+// solution carries the pair spectrum coefficients found there
+// (PairCoeffsReady is set).
+func criticalEnvs(env *tempAll.Environment, epsAbs, epsRel float64) (*tempAll.Environment, *tempAll.Environment, error) {
+	// find T_p
+	tpEnv := env.Copy()
+	tpEnv.F0, tpEnv.Mu_b = 0.0, 0.0
+	_, err := tempPair.PairTempSolve(tpEnv, epsAbs, epsRel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find T_p: %v", err)
+	}
+	// find T_c and the pair spectrum there
+	tcEnv := env.Copy()
+	tcEnv.F0, tcEnv.Mu_b = 0.0, 0.0
+	_, err = tempCrit.CritTempSolve(tcEnv, epsAbs, epsRel)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find T_c: %v", err)
+	}
+	omegaFit, err := tempCrit.OmegaFit(tcEnv, tempCrit.OmegaPlus)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to find pair spectrum at T_c: %v", err)
+	}
+	err = tempCrit.FixPairSpectrum(tcEnv, omegaFit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to tabulate pair spectrum at T_c: %v", err)
+	}
+	return tpEnv, tcEnv, nil
+}
+
+// Store the solution at index i.
+func (result *ScanResult) record(i int, env *ScanEnv, err error) {
+	if err != nil {
+		fmt.Printf("Error: %v; produced while solving scan at T = %f\n", err, result.Temps[i])
+		result.Errs[i] = err
+		return
+	}
+	result.Envs[i] = *env
+}
+
+// Return the regime which contains temperature T.
+func RegimeOf(T, Tc, Tp float64) int {
+	if T == 0.0 {
+		return ZeroTemp
+	} else if T < Tc {
+		return LowTemp
+	} else if T < Tp {
+		return FlucTemp
+	}
+	return NormalTemp
+}
+
+// Solve at temperature T, starting from the solution start.
+func solveAt(start *tempAll.Environment, T, Tc, Tp, epsAbs, epsRel float64) (*ScanEnv, error) {
+	env := start.Copy()
+	env.Temp = T
+	if T == 0.0 {
+		env.Beta = math.Inf(1)
+	} else {
+		env.Beta = 1.0 / T
+	}
+	regime := RegimeOf(T, Tc, Tp)
+	x2 := 0.0
+	switch regime {
+	case ZeroTemp:
+		env.Mu_b = 0.0
+		if env.F0 == 0.0 {
+			env.F0 = 0.1
+		}
+		_, err := tempZero.ZeroTempSolve(env, epsAbs, epsRel)
+		if err != nil {
+			return nil, err
+		}
+	case LowTemp:
+		env.Mu_b = 0.0
+		if env.F0 == 0.0 {
+			// leaving T_c: order parameter is small but nonzero
+			env.F0 = 0.01
+		}
+		_, err := tempLow.D1MuF0Solve(env, epsAbs, epsRel)
+		if err != nil {
+			return nil, err
+		}
+		x2, err = tempLow.X2(env)
+		if err != nil {
+			return nil, err
+		}
+	case FlucTemp:
+		env.F0 = 0.0
+		err := solveFlucMu_b(env, epsAbs, epsRel)
+		if err != nil {
+			return nil, err
+		}
+		x2, err = tempCrit.X2(env)
+		if err != nil {
+			return nil, err
+		}
+	case NormalTemp:
+		// no pairs above T_p; don't carry Mu_b over from T < T_p
+		env.F0 = 0.0
+		env.Mu_b = 0.0
+		_, err := tempPair.D1MuSolve(env, epsAbs, epsRel)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &ScanEnv{*env, x2, regime}, nil
+}
+
+// Solve for (D1, Mu_h, Mu_b) in T_c <= T < T_p with X and Beta fixed. The
+// pair pole condition solved by tempFluc.SolveD1Mu_h places omega_+(0) at
+// Mu_b for any Mu_b (so tempFluc.SolveD1Mu_hMu_b leaves Mu_b where it
+// starts); Mu_b is instead fixed by x = x_1 + x_2.
+func solveFlucMu_b(env *tempAll.Environment, epsAbs, epsRel float64) error {
+	F := func(v vec.Vector) (float64, error) {
+		env.Mu_b = v[0]
+		_, err := tempFluc.SolveD1Mu_h(env, epsAbs, epsRel)
+		if err != nil {
+			return 0.0, err
+		}
+		x2, err := tempCrit.X2(env)
+		if err != nil {
+			return 0.0, err
+		}
+		return env.X - tempPair.X1(env) - x2, nil
+	}
+	diff := solve.SimpleDiffable(F, 1, 1e-5, 1e-4)
+	// x_1 + x_2 > x at Mu_b = 0 above T_c; expand the lower bound until
+	// there are too few pairs.
+	hi := 0.0
+	lo := math.Min(env.Mu_b, -1e-4)
+	maxExpand := 20
+	for i := 0; ; i++ {
+		errLo, err := diff.F([]float64{lo})
+		if err != nil {
+			return err
+		}
+		if errLo > 0.0 {
+			break
+		}
+		if i == maxExpand {
+			return fmt.Errorf("failed to bracket Mu_b (error %e at Mu_b = %e)", errLo, lo)
+		}
+		hi = lo
+		lo *= 2.0
+	}
+	Mu_b, err := solve.Brent(diff, lo, hi, epsAbs, epsRel)
+	if err != nil {
+		return err
+	}
+	// leave env in the solved state
+	_, err = diff.F([]float64{Mu_b})
+	return err
+}
@@ -0,0 +1,295 @@
+package tempScan
+
+import (
+	"flag"
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempPair"
+	"github.com/tflovorn/scExplorer/units"
+)
+
+var testPlot = flag.Bool("testPlot", false, "Run tests involving plots")
+var checkThermo = flag.Bool("checkThermo", false, "Run thermodynamic consistency test")
+
+// Temperatures should be assigned to the appropriate regime.
+func TestRegimeOf(t *testing.T) {
+	Tc, Tp := 0.25, 0.4
+	cases := map[float64]int{0.0: ZeroTemp, 0.1: LowTemp, Tc: FlucTemp, 0.3: FlucTemp, Tp: NormalTemp, 0.5: NormalTemp}
+	for T, expected := range cases {
+		if RegimeOf(T, Tc, Tp) != expected {
+			t.Fatalf("incorrect regime %d for T = %f", RegimeOf(T, Tc, Tp), T)
+		}
+	}
+}
+
+// Above T_p there are no pairs, so Mu_b from a solution below T_p should not
+// be carried over.
+func TestSolveAtNormal(t *testing.T) {
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Mu_b, env.F0 = -0.1, 0.05
+	eps := 1e-9
+	s, err := solveAt(env, 0.5, 0.1, 0.2, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Regime != NormalTemp || s.Mu_b != 0.0 || s.F0 != 0.0 || s.X2 != 0.0 {
+		t.Fatalf("pair quantities not cleared above T_p: %v", s)
+	}
+}
+
+// Plotted temperatures and energies should be converted to K and meV when
+// a physical energy scale is given, and left alone otherwise.
+func TestPhysicalUnits(t *testing.T) {
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if physicalTemps(env, []float64{0.1})[0] != 0.1 || physicalEnergies(env, []float64{0.1})[0] != 0.1 {
+		t.Fatalf("values converted without a physical energy scale")
+	}
+	env.PhysicalT0, env.PhysicalT0Unit = 100.0*env.T0, "K"
+	if T := physicalTemps(env, []float64{0.1})[0]; math.Abs(T-10.0) > 1e-12 {
+		t.Fatalf("T = 0.1 t_0 converted to %v K; expected 10 K", T)
+	}
+	if E := physicalEnergies(env, []float64{0.1})[0]; math.Abs(E-10.0*units.MEV_PER_K) > 1e-12 {
+		t.Fatalf("E = 0.1 t_0 converted to %v meV; expected %v meV", E, 10.0*units.MEV_PER_K)
+	}
+	params := map[string]string{plots.YLABEL_KEY: "custom"}
+	setLabels(params, units.TempLabel(env, "T"), "unused")
+	if params[plots.XLABEL_KEY] != "$T$ (K)" || params[plots.YLABEL_KEY] != "custom" {
+		t.Fatalf("incorrect labels %v", params)
+	}
+}
+
+func scanDefaultEnv() (*tempAll.Environment, error) {
+	data, err := ioutil.ReadFile("scan_test_env.json")
+	if err != nil {
+		return nil, err
+	}
+	env, err := tempAll.NewEnvironment(string(data))
+	if err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// Plot D1, Mu_h, Mu_b, F0 and x2 from T = 0 to above T_p.
+func TestPlotThermalScan(t *testing.T) {
+	flag.Parse()
+	if !*testPlot {
+		return
+	}
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	N := 20
+	temps := make([]float64, N)
+	for i := 0; i < N; i++ {
+		temps[i] = 0.5 * float64(i) / float64(N-1)
+	}
+	eps := 1e-9
+	result, err := ThermalScan(env, temps, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	grapherPath := wd + "/../plots/grapher.py"
+	syms := map[string]string{"D1": "D_1", "Mu_h": "\\mu_h", "Mu_b": "\\mu_b", "F0": "F_0", "X2": "x_2"}
+	energies := map[string]bool{"Mu_h": true, "Mu_b": true}
+	for y, sym := range syms {
+		graphParams := map[string]string{plots.FILE_KEY: wd + "/plot_data.scan_" + y}
+		err = result.Plot(y, sym, energies[y], graphParams, grapherPath)
+		if err != nil {
+			t.Fatalf("error making %s(T) plot: %v", y, err)
+		}
+	}
+}
+
+// Plot rho_s(T) at the default doping and T_c vs rho_s(0) across doping.
+func TestPlotSuperfluidStiffness(t *testing.T) {
+	flag.Parse()
+	if !*testPlot {
+		return
+	}
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := 1e-9
+	wd, _ := os.Getwd()
+	grapherPath := wd + "/../plots/grapher.py"
+	N := 20
+	temps := make([]float64, N)
+	for i := 0; i < N; i++ {
+		temps[i] = 0.2 * float64(i) / float64(N-1)
+	}
+	result, err := ThermalScan(env, temps, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Ts, rhos, err := Stiffness(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphParams := map[string]string{plots.FILE_KEY: wd + "/plot_data.rho_s_T"}
+	err = PlotStiffness(env, Ts, rhos, graphParams, grapherPath)
+	if err != nil {
+		t.Fatalf("error making rho_s(T) plot: %v", err)
+	}
+	Xs := []float64{0.05, 0.075, 0.1, 0.125, 0.15}
+	Tcs, rho0s := Uemura(env, Xs, eps, eps)
+	graphParams = map[string]string{plots.FILE_KEY: wd + "/plot_data.uemura"}
+	err = PlotUemura(env, Tcs, rho0s, graphParams, grapherPath)
+	if err != nil {
+		t.Fatalf("error making Uemura plot: %v", err)
+	}
+}
+
+// Gap ratios of unsolved d-wave Environments with known gap amplitude.
+func TestGapRatios(t *testing.T) {
+	zeroEnv, err := tempAll.NewEnvironment(`{"PointsPerSide": 16, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Mu_h": 0.5, "F0": 0.1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zeroEnv.Beta = math.Inf(1)
+	tcEnv, tpEnv := zeroEnv.Copy(), zeroEnv.Copy()
+	tcEnv.Beta, tpEnv.Beta = 10.0, 5.0
+	r, err := GapRatios(zeroEnv, tcEnv, tpEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// max |sin(kx) - sin(ky)| = 2 is on the grid
+	expectedMax := 8.0 * 1.1 * 0.1
+	if math.Abs(r.DeltaMax-expectedMax) > 1e-12 || math.Abs(r.GapRatio-20.0*expectedMax) > 1e-10 {
+		t.Fatalf("incorrect gap maximum or ratio: %v", r)
+	}
+	if math.Abs(r.TpTc-2.0) > 1e-12 {
+		t.Fatalf("incorrect T_p/T_c: %v", r)
+	}
+	if !r.HasFermiSurface || r.NodalGap > 1e-2*r.Antinodal {
+		t.Fatalf("expected a Fermi surface with d-wave nodes: %v", r)
+	}
+}
+
+// The three-point derivative should be exact for a quadratic on an uneven
+// grid.
+func TestDerivative(t *testing.T) {
+	xs := []float64{0.0, 0.1, 0.3, 0.35, 0.7}
+	ys := make([]float64, len(xs))
+	for i, x := range xs {
+		ys[i] = 2.0*x*x - x + 1.0
+	}
+	d := derivative(xs, ys)
+	for i := 1; i < len(xs)-1; i++ {
+		if math.Abs(d[i]-(4.0*xs[i]-1.0)) > 1e-12 {
+			t.Fatalf("incorrect derivative %v at x = %v", d[i], xs[i])
+		}
+	}
+}
+
+// The entropy from integrating the specific heat C/T should match -dF/dT
+// across T_c.
+func TestEntropyConsistency(t *testing.T) {
+	flag.Parse()
+	if !*checkThermo {
+		return
+	}
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	N := 40
+	temps := make([]float64, N)
+	for i := 0; i < N; i++ {
+		temps[i] = 0.02 + 0.48*float64(i)/float64(N-1)
+	}
+	eps := 1e-9
+	result, err := ThermalScan(env, temps, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	th, err := CheckEntropy(result, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("T_c = %g, T_p = %g; max relative entropy error %g", result.Tc, result.Tp, th.MaxRelErr)
+}
+
+// Above T_c, Mu_b should move away from 0 so that x = x_1 + x_2 holds.
+func TestFlucTempConservesX(t *testing.T) {
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.PointsPerSide = 8
+	eps := 1e-6
+	tpEnv, tcEnv, err := criticalEnvs(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Tp, Tc := 1.0/tpEnv.Beta, 1.0/tcEnv.Beta
+	flucEnv, err := solveAt(tcEnv, 1.05*Tc, Tc, Tp, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x1 := tempPair.X1(&flucEnv.Environment)
+	if math.Abs(flucEnv.X-x1-flucEnv.X2) > 1e-6 || flucEnv.Mu_b >= 0.0 {
+		t.Fatalf("fluctuation regime solution has x = %v, x_1 + x_2 = %v, Mu_b = %v", flucEnv.X, x1+flucEnv.X2, flucEnv.Mu_b)
+	}
+}
+
+// In each regime, the entropy from FreeEnergy should be -dOmega/dT at fixed
+// chemical potentials, D1 and F0, on a small grid.
+func TestEntropyConsistencySmall(t *testing.T) {
+	env, err := scanDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.PointsPerSide = 16
+	// Fixed pair coefficients keep the pair spectrum independent of T. The
+	// Bogoliubov spectrum moves with T through PairCoeffs and PairGap, so
+	// -dOmega/dT would pick up terms which are absent from the entropy.
+	env.A, env.B, env.FixedPairCoeffs, env.PairCoeffsReady = 0.65, 0.09, true, true
+	cases := []struct {
+		regime   int
+		F0, Mu_b float64
+	}{
+		{NormalTemp, 0.0, 0.0},
+		{FlucTemp, 0.0, -0.01},
+		{LowTemp, 0.01, 0.0},
+	}
+	for _, c := range cases {
+		scanEnv := ScanEnv{*env.Copy(), 0.0, c.regime}
+		scanEnv.F0, scanEnv.Mu_b = c.F0, c.Mu_b
+		_, S, err := FreeEnergy(&scanEnv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		grand := func(T float64) float64 {
+			shifted := ScanEnv{*scanEnv.Copy(), 0.0, c.regime}
+			shifted.Beta = 1.0 / T
+			omega, err := GrandPotential(&shifted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return omega
+		}
+		h := 1e-4
+		T := 1.0 / scanEnv.Beta
+		SFromOmega := -(grand(T+h) - grand(T-h)) / (2.0 * h)
+		t.Logf("regime %d: S = %v, -dOmega/dT = %v", c.regime, S, SFromOmega)
+		if math.Abs(S-SFromOmega) > 1e-4*math.Abs(S) {
+			t.Fatalf("regime %d: entropy %v differs from -dOmega/dT = %v", c.regime, S, SFromOmega)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package tempScan
+
+import "fmt"
+import (
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempLow"
+)
+
+// Superfluid stiffness rho_s(T) at each temperature of result. rho_s = 0 at
+// and above T_c; temperatures which were not solved are skipped.
+func Stiffness(result *ScanResult) ([]float64, []float64, error) {
+	Ts, rhos := []float64{}, []float64{}
+	for i, T := range result.Temps {
+		if result.Errs[i] != nil {
+			continue
+		}
+		env := result.Envs[i].(ScanEnv)
+		rho := 0.0
+		if env.Regime == ZeroTemp || env.Regime == LowTemp {
+			var err error
+			rho, err = tempLow.SuperfluidStiffness(&env.Environment)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to find stiffness at T = %f: %v", T, err)
+			}
+		}
+		Ts = append(Ts, T)
+		rhos = append(rhos, rho)
+	}
+	return Ts, rhos, nil
+}
+
+// T_c and the zero-temperature stiffness rho_s(0) at each doping in Xs, for a
+// Uemura plot of T_c vs rho_s(0). Dopings at which either could not be found
+// are skipped.
+func Uemura(env *tempAll.Environment, Xs []float64, epsAbs, epsRel float64) ([]float64, []float64) {
+	Tcs, rho0s := []float64{}, []float64{}
+	for _, X := range Xs {
+		xEnv := env.Copy()
+		xEnv.X = X
+		result, err := ThermalScan(xEnv, []float64{0.0}, epsAbs, epsRel)
+		if err != nil {
+			fmt.Printf("Error: %v; produced while finding T_c at x = %f\n", err, X)
+			continue
+		}
+		_, rhos, err := Stiffness(result)
+		if err != nil || len(rhos) == 0 {
+			fmt.Printf("Error: no zero-temperature stiffness at x = %f (%v)\n", X, err)
+			continue
+		}
+		Tcs = append(Tcs, result.Tc)
+		rho0s = append(rho0s, rhos[0])
+	}
+	return Tcs, rho0s
+}
@@ -0,0 +1,188 @@
+package tempScan
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/integrate"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempCrit"
+	"github.com/tflovorn/scExplorer/tempFluc"
+	"github.com/tflovorn/scExplorer/tempLow"
+	"github.com/tflovorn/scExplorer/tempZero"
+)
+
+// Thermodynamics along a ThermalScan at fixed X, over the solved points in
+// the low-temperature and fluctuation regimes, in order of ascending
+// temperature. All quantities are per site.
+type Thermo struct {
+	Temps  []float64
+	F      []float64 // Helmholtz free energy, holons plus pairs
+	S      []float64 // entropy from the distribution functions, (U - F)/T
+	C      []float64 // specific heat from the regime's HolonSpecificHeat and PairSpecificHeat
+	SFromF []float64 // -dF/dT
+	SFromC []float64 // S(Temps[0]) + int_{Temps[0]}^{T} C/T' dT'
+	// max_i |SFromC[i] - SFromF[i]| / max_i |SFromF[i]|
+	MaxRelErr float64
+}
+
+type envFunc func(*tempAll.Environment) (float64, error)
+
+// Helmholtz free energy and entropy of env, holons plus pairs, using the
+// free energy functions of its regime.
+func FreeEnergy(env *ScanEnv) (float64, float64, error) {
+	var Fs, Ss []envFunc
+	switch env.Regime {
+	case ZeroTemp:
+		return tempZero.GroundStateEnergy(&env.Environment), 0.0, nil
+	case LowTemp:
+		Fs = []envFunc{tempLow.HolonFreeEnergy, tempLow.PairFreeEnergy}
+		Ss = []envFunc{tempLow.HolonEntropy, tempLow.PairEntropy}
+	case FlucTemp:
+		Fs = []envFunc{tempCrit.HolonFreeEnergy, tempCrit.PairFreeEnergy}
+		Ss = []envFunc{tempCrit.HolonEntropy, tempCrit.PairEntropy}
+	case NormalTemp:
+		Fs = []envFunc{tempCrit.HolonFreeEnergy}
+		Ss = []envFunc{tempCrit.HolonEntropy}
+	default:
+		return 0.0, 0.0, fmt.Errorf("unknown regime %d", env.Regime)
+	}
+	F, err := sumEnvFuncs(&env.Environment, Fs)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	S, err := sumEnvFuncs(&env.Environment, Ss)
+	if err != nil {
+		return 0.0, 0.0, err
+	}
+	return F, S, nil
+}
+
+// Grand potential of env, holons plus pairs, using the grand potential
+// functions of its regime. Its derivative with respect to T at fixed
+// chemical potentials, D1 and F0 is minus the entropy given by FreeEnergy.
+func GrandPotential(env *ScanEnv) (float64, error) {
+	var Omegas []envFunc
+	switch env.Regime {
+	case LowTemp:
+		Omegas = []envFunc{tempLow.HolonGrandPotential, tempLow.PairGrandPotential}
+	case FlucTemp:
+		Omegas = []envFunc{tempCrit.HolonGrandPotential, tempCrit.PairGrandPotential}
+	case NormalTemp:
+		Omegas = []envFunc{tempCrit.HolonGrandPotential}
+	default:
+		return 0.0, fmt.Errorf("grand potential is not implemented in regime %d", env.Regime)
+	}
+	return sumEnvFuncs(&env.Environment, Omegas)
+}
+
+// Specific heat of env, holons plus pairs, from the specific heat functions
+// of its regime. These differentiate the internal energy along the solution
+// and do not use the free energy or entropy. Only the low-temperature and
+// fluctuation regimes are implemented.
+func SpecificHeat(env *ScanEnv) (float64, error) {
+	var Cs []envFunc
+	switch env.Regime {
+	case LowTemp:
+		Cs = []envFunc{tempLow.HolonSpecificHeat, tempLow.PairSpecificHeat}
+	case FlucTemp:
+		Cs = []envFunc{tempFluc.HolonSpecificHeat, tempFluc.PairSpecificHeat}
+	default:
+		return 0.0, fmt.Errorf("specific heat is not implemented in regime %d", env.Regime)
+	}
+	return sumEnvFuncs(&env.Environment, Cs)
+}
+
+// Sum of the functions fs evaluated at env.
+func sumEnvFuncs(env *tempAll.Environment, fs []envFunc) (float64, error) {
+	sum := 0.0
+	for _, f := range fs {
+		v, err := f(env)
+		if err != nil {
+			return 0.0, err
+		}
+		sum += v
+	}
+	return sum, nil
+}
+
+// Find the free energy, entropy and specific heat at each solved point of
+// result in the low-temperature and fluctuation regimes, and check
+// thermodynamic consistency: the entropy found by integrating C/T, with C
+// from SpecificHeat, must match -dF/dT to within the relative tolerance tol.
+// -dF/dT is taken by finite differences, so the temperatures should be
+// closely spaced. If the check fails, the Thermo is returned along with an
+// error.
+func CheckEntropy(result *ScanResult, tol float64) (*Thermo, error) {
+	th := &Thermo{}
+	for i, ie := range result.Envs {
+		if ie == nil {
+			continue
+		}
+		env := ie.(ScanEnv)
+		if env.Regime != LowTemp && env.Regime != FlucTemp {
+			continue
+		}
+		F, S, err := FreeEnergy(&env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find free energy at T = %f: %v", result.Temps[i], err)
+		}
+		C, err := SpecificHeat(&env)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find specific heat at T = %f: %v", result.Temps[i], err)
+		}
+		th.Temps = append(th.Temps, result.Temps[i])
+		th.F = append(th.F, F)
+		th.S = append(th.S, S)
+		th.C = append(th.C, C)
+	}
+	N := len(th.Temps)
+	if N < 3 {
+		return nil, fmt.Errorf("entropy check needs at least 3 solved temperatures; got %d", N)
+	}
+	dF := derivative(th.Temps, th.F)
+	th.SFromF = make([]float64, N)
+	gammas := make([]float64, N)
+	for i, T := range th.Temps {
+		th.SFromF[i] = -dF[i]
+		gammas[i] = th.C[i] / T
+	}
+	th.SFromC = make([]float64, N)
+	th.SFromC[0] = th.S[0]
+	scale, maxDiff := 0.0, math.Abs(th.SFromC[0]-th.SFromF[0])
+	for i := 1; i < N; i++ {
+		integral, err := integrate.Spline(th.Temps, gammas, th.Temps[0], th.Temps[i])
+		if err != nil {
+			return nil, err
+		}
+		th.SFromC[i] = th.S[0] + integral
+		maxDiff = math.Max(maxDiff, math.Abs(th.SFromC[i]-th.SFromF[i]))
+	}
+	for _, S := range th.SFromF {
+		scale = math.Max(scale, math.Abs(S))
+	}
+	if scale == 0.0 {
+		return th, fmt.Errorf("entropy check: -dF/dT vanishes at all temperatures")
+	}
+	th.MaxRelErr = maxDiff / scale
+	if th.MaxRelErr > tol {
+		return th, fmt.Errorf("entropy from C/T differs from -dF/dT by %g (relative); tolerance %g", th.MaxRelErr, tol)
+	}
+	return th, nil
+}
+
+// Derivative of ys with respect to xs (sorted, distinct), using the
+// three-point formula for unevenly spaced points in the interior and
+// one-sided differences at the ends.
+func derivative(xs, ys []float64) []float64 {
+	N := len(xs)
+	d := make([]float64, N)
+	d[0] = (ys[1] - ys[0]) / (xs[1] - xs[0])
+	d[N-1] = (ys[N-1] - ys[N-2]) / (xs[N-1] - xs[N-2])
+	for i := 1; i < N-1; i++ {
+		h1, h2 := xs[i]-xs[i-1], xs[i+1]-xs[i]
+		d[i] = (h1*(ys[i+1]-ys[i])/h2 + h2*(ys[i]-ys[i-1])/h1) / (h1 + h2)
+	}
+	return d
+}
@@ -0,0 +1,44 @@
+package tempZero
+
+import "math"
+import (
+	"github.com/tflovorn/scExplorer/bzone"
+	"github.com/tflovorn/scExplorer/solve"
+	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
+)
+
+// Ground state energy of the holons, the T = 0 limit of the free energy
+// F/N = 1/N \sum_k (\xi_k - E_k)/2 + 4 (T0 + Tz) F0^2 + \mu_h x
+// (see tempLow.HolonGrandPotential). All pairs are condensed at T = 0.
+func GroundStateEnergy(env *tempAll.Environment) float64 {
+	inner := func(k vec.Vector) float64 {
+		return (env.Xi_h(k) - env.BogoEnergy(k)) / 2.0
+	}
+	avg := bzone.Avg(env.PointsPerSide, env.HolonDim(), inner)
+	return avg + 4.0*(env.T0+env.Tz)*env.F0*env.F0 + env.Mu_h*env.X
+}
+
+// Condensation energy F_n - F_s at T = 0, where F_s is the ground state
+// energy of the solved superconducting state env and F_n is that of the
+// normal state (F0 = 0) at the same x and D1. Positive if the
+// superconducting state is stable.
+func CondensationEnergy(env *tempAll.Environment, epsAbs, epsRel float64) (float64, error) {
+	normal := env.Copy()
+	normal.F0 = 0.0
+	normal.Beta = math.Inf(1)
+	// At F0 = 0, x = <theta(-xi_k)>_k is a step function of Mu_h on the
+	// finite grid, so bracket the root between the band edges instead of
+	// following the (vanishing) derivative.
+	negEpsilon := func(k vec.Vector) float64 {
+		return -normal.Epsilon_h(k)
+	}
+	bandTop := -bzone.Min(normal.PointsPerSide, normal.HolonDim(), negEpsilon)
+	diff := AbsErrorMu_hNoninteracting(normal, []string{"Mu_h"})
+	Mu_h, err := solve.Brent(diff, -1.0, bandTop+1.0, epsAbs, epsRel)
+	if err != nil {
+		return 0.0, err
+	}
+	normal.Mu_h = Mu_h
+	return GroundStateEnergy(normal) - GroundStateEnergy(env), nil
+}
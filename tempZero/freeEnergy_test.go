@@ -0,0 +1,24 @@
+package tempZero
+
+import "testing"
+
+// The superconducting ground state should be lower in energy than the normal
+// state at the same x.
+func TestCondensationEnergy(t *testing.T) {
+	env, err := ztDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := 1e-9
+	_, err = ZeroTempSolve(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Ec, err := CondensationEnergy(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if Ec <= 0.0 {
+		t.Fatalf("condensation energy %v is not positive", Ec)
+	}
+}
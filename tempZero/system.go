@@ -6,13 +6,22 @@ import (
 	vec "github.com/tflovorn/scExplorer/vector"
 )
 
+// For a mixed gap (s+d or d+is), F1 is also solved for.
 func ZeroTempSystem(env *tempAll.Environment) (solve.DiffSystem, []float64) {
 	variables := []string{"D1", "Mu_h", "F0"}
+	start := []float64{env.D1, env.Mu_h, env.F0}
+	if env.MixedGap() {
+		variables = append(variables, "F1")
+		start = append(start, env.F1)
+	}
 	diffD1 := AbsErrorD1(env, variables)
 	diffMu_h := AbsErrorMu_h(env, variables)
 	diffF0 := AbsErrorF0(env, variables)
-	system := solve.Combine([]solve.Diffable{diffD1, diffMu_h, diffF0})
-	start := []float64{env.D1, env.Mu_h, env.F0}
+	diffs := []solve.Diffable{diffD1, diffMu_h, diffF0}
+	if env.MixedGap() {
+		diffs = append(diffs, AbsErrorF1(env, variables))
+	}
+	system := solve.Combine(diffs)
 	return system, start
 }
 
@@ -17,7 +17,7 @@ func AbsErrorD1(env *tempAll.Environment, variables []string) solve.Diffable {
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := env.D1
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerD1))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerD1))
 		return lhs - rhs, nil
 	}
 	h := 1e-6
@@ -37,7 +37,7 @@ func AbsErrorD1Noninteracting(env *tempAll.Environment, variables []string) solv
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := env.D1
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerD1Noninteracting))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerD1Noninteracting))
 		return lhs - rhs, nil
 	}
 	h := 1e-6
@@ -1,8 +1,6 @@
 package tempZero
 
-import "math"
 import (
-	"github.com/tflovorn/scExplorer/bzone"
 	"github.com/tflovorn/scExplorer/solve"
 	"github.com/tflovorn/scExplorer/tempAll"
 	vec "github.com/tflovorn/scExplorer/vector"
@@ -11,12 +9,21 @@ import (
 // Return the absolute error and gradient of the order parameter equation
 // w.r.t. the given variables.
 func AbsErrorF0(env *tempAll.Environment, variables []string) solve.Diffable {
+	return absErrorGap(env, variables, 0)
+}
+
+// Return the absolute error and gradient of the gap equation for the second
+// component of a mixed gap (which determines F1) w.r.t. the given variables.
+func AbsErrorF1(env *tempAll.Environment, variables []string) solve.Diffable {
+	return absErrorGap(env, variables, 1)
+}
+
+// Error in the i'th of the coupled gap equations
+// (see tempAll.Environment.GapEquationErrors).
+func absErrorGap(env *tempAll.Environment, variables []string, i int) solve.Diffable {
 	F := func(v vec.Vector) (float64, error) {
 		env.Set(v, variables)
-		L := env.PointsPerSide
-		lhs := 1.0 / (env.T0 + env.Tz)
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerF0))
-		return lhs - rhs, nil
+		return env.GapEquationErrors(tempAll.WrapFunc(env, innerF0))[i], nil
 	}
 	h := 1e-6
 	epsabs := 1e-4
@@ -24,6 +31,5 @@ func AbsErrorF0(env *tempAll.Environment, variables []string) solve.Diffable {
 }
 
 func innerF0(env *tempAll.Environment, k vec.Vector) float64 {
-	sxy := math.Sin(k[0]) + float64(env.Alpha)*math.Sin(k[1])
-	return sxy * sxy / env.BogoEnergy(k)
+	return 1.0 / env.BogoEnergy(k)
 }
@@ -14,7 +14,7 @@ func AbsErrorMu_h(env *tempAll.Environment, variables []string) solve.Diffable {
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := env.X
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerMu_h))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerMu_h))
 		return lhs - rhs, nil
 	}
 	h := 1e-6
@@ -33,7 +33,7 @@ func AbsErrorMu_hNoninteracting(env *tempAll.Environment, variables []string) so
 		env.Set(v, variables)
 		L := env.PointsPerSide
 		lhs := env.X
-		rhs := bzone.Avg(L, 2, tempAll.WrapFunc(env, innerMu_hNoninteracting))
+		rhs := bzone.Avg(L, env.HolonDim(), tempAll.WrapFunc(env, innerMu_hNoninteracting))
 		return lhs - rhs, nil
 	}
 	h := 1e-6
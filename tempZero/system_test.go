@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"strconv"
 	"testing"
@@ -11,6 +12,7 @@ import (
 import (
 	"github.com/tflovorn/scExplorer/plots"
 	"github.com/tflovorn/scExplorer/tempAll"
+	vec "github.com/tflovorn/scExplorer/vector"
 )
 
 var production = flag.Bool("production", false, "Production mode: make plots that shouldn't change.")
@@ -233,3 +235,33 @@ func solveAndPlot(envs []*tempAll.Environment, epsabs, epsrel float64, vars plot
 	}
 	return nil
 }
+
+// A d+is gap should solve the coupled gap equations for F0 and F1 together.
+// Here the s channel is subdominant, so the solution should relax to the
+// pure d-wave one of TestSolveZeroTempSystem.
+func TestSolveZeroTempMixedGap(t *testing.T) {
+	env, err := ztDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	env.Gap = []tempAll.GapComponent{{Basis: "d", Re: 1.0}, {Basis: "s", Im: 1.0}}
+	env.F1 = 0.3
+	eps := 1e-9
+	_, err = ZeroTempSolve(env, eps, eps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("D1 = %v, Mu_h = %v, F0 = %v, F1 = %v", env.D1, env.Mu_h, env.F0, env.F1)
+	W := func(k vec.Vector) float64 {
+		return 1.0 / env.BogoEnergy(k)
+	}
+	for i, e := range env.GapEquationErrors(W) {
+		if math.Abs(e) > 1e-6 {
+			t.Fatalf("gap equation %d not solved: error %v", i, e)
+		}
+	}
+	expectedF0 := 0.13007419282082078
+	if math.Abs(env.F1) > 1e-6 || math.Abs(env.F0-expectedF0) > 1e-6 {
+		t.Fatalf("mixed gap gave F0 = %v, F1 = %v; expected pure d-wave F0 = %v", env.F0, env.F1, expectedF0)
+	}
+}
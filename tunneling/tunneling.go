@@ -0,0 +1,129 @@
+// Simulated tunneling (STM) spectra.
+//
+// The tunneling conductance between a normal tip and the sample is
+// dI/dV(V) = int d omega N_qp(omega) [-df(omega - V)/d omega], with N_qp the
+// Bogolyubov quasiparticle density of states (dos.Quasiparticle) and f the
+// Fermi function at the sample temperature; V is in units of T0/e. The Dynes
+// parameter Gamma gives each quasiparticle state a Lorentzian lifetime
+// broadening, which fills in the gap.
+package tunneling
+
+import (
+	"fmt"
+	"math"
+)
+import (
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/plots"
+	"github.com/tflovorn/scExplorer/tempAll"
+	"github.com/tflovorn/scExplorer/tempScan"
+)
+
+// Energy steps per temperature (or per Dynes width, if smaller) in the
+// thermal convolution.
+const STEPS_PER_WIDTH = 8
+
+// The tunneling conductance dI/dV at each bias in Vs, with Dynes broadening
+// Gamma (0 for none).
+func DIdV(env *tempAll.Environment, Vs []float64, Gamma float64) ([]float64, error) {
+	if Gamma < 0.0 {
+		return nil, fmt.Errorf("Dynes parameter must be non-negative; got %v", Gamma)
+	}
+	if len(Vs) == 0 {
+		return []float64{}, nil
+	}
+	b := dos.Broadening{Kind: "lorentzian", Width: Gamma}
+	if math.IsInf(env.Beta, 1) {
+		// -df/d omega = delta(omega - V)
+		return dos.Quasiparticle(env, Vs, b)
+	}
+	minV, maxV := Vs[0], Vs[0]
+	for _, V := range Vs {
+		minV, maxV = math.Min(minV, V), math.Max(maxV, V)
+	}
+	T := 1.0 / env.Beta
+	step := T / STEPS_PER_WIDTH
+	if Gamma > 0.0 {
+		step = math.Min(step, Gamma/STEPS_PER_WIDTH)
+	}
+	// -df/d omega is negligible more than 20 T away from V
+	cut := 20.0 * T
+	N := int(math.Ceil((maxV-minV+2.0*cut)/step)) + 1
+	omegas := dos.Omegas(minV-cut, maxV+cut, N)
+	Ns, err := dos.Quasiparticle(env, omegas, b)
+	if err != nil {
+		return nil, err
+	}
+	dw := omegas[1] - omegas[0]
+	result := make([]float64, len(Vs))
+	for i, V := range Vs {
+		total := 0.0
+		for j, omega := range omegas {
+			f := env.Fermi(omega - V)
+			total += Ns[j] * env.Beta * f * (1.0 - f)
+		}
+		result[i] = total * dw
+	}
+	return result, nil
+}
+
+// dI/dV normalized by the conductance of the same Environment with the gap
+// removed (F0 = 0), as is usual for experimental spectra.
+func NormalizedDIdV(env *tempAll.Environment, Vs []float64, Gamma float64) ([]float64, error) {
+	sc, err := DIdV(env, Vs, Gamma)
+	if err != nil {
+		return nil, err
+	}
+	normalEnv := env.Copy()
+	normalEnv.F0 = 0.0
+	normal, err := DIdV(normalEnv, Vs, Gamma)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sc {
+		sc[i] /= normal[i]
+	}
+	return sc, nil
+}
+
+// Normalized dI/dV curves at each temperature of a thermal scan at or below
+// T_c (plus the first temperature above T_c, for reference). Returns the
+// temperatures and the corresponding curves.
+func TemperatureSweep(result *tempScan.ScanResult, Vs []float64, Gamma float64) ([]float64, [][]float64, error) {
+	Ts, curves := []float64{}, [][]float64{}
+	aboveTc := false
+	for i, T := range result.Temps {
+		if result.Errs[i] != nil {
+			continue
+		}
+		if T >= result.Tc {
+			if aboveTc {
+				break
+			}
+			aboveTc = true
+		}
+		env := result.Envs[i].(tempScan.ScanEnv)
+		curve, err := NormalizedDIdV(&env.Environment, Vs, Gamma)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find dI/dV at T = %f: %v", T, err)
+		}
+		Ts = append(Ts, T)
+		curves = append(curves, curve)
+	}
+	return Ts, curves, nil
+}
+
+// Plot the output of TemperatureSweep, with one curve per temperature.
+func PlotSweep(Ts []float64, Vs []float64, curves [][]float64, outPrefix, grapherPath string) error {
+	data := make([]plots.Series, len(curves))
+	for i, curve := range curves {
+		data[i] = plots.MakeSeries(Vs, curve)
+	}
+	seriesParams := plots.MakeSeriesParams("T", "%.3f", Ts, plots.COLOR_STYLES)
+	params := map[string]string{
+		plots.FILE_KEY:   outPrefix,
+		plots.XLABEL_KEY: "$eV/t_0$",
+		plots.YLABEL_KEY: "$(dI/dV) / (dI/dV)_n$",
+	}
+	return plots.PlotMPL(data, params, seriesParams, grapherPath)
+}
@@ -0,0 +1,68 @@
+package tunneling
+
+import (
+	"math"
+	"testing"
+)
+import (
+	"github.com/tflovorn/scExplorer/dos"
+	"github.com/tflovorn/scExplorer/tempAll"
+)
+
+// Thermal broadening conserves spectral weight, so dI/dV should integrate to
+// 1 over a bias range covering the band.
+func TestDIdVWeight(t *testing.T) {
+	env, err := tunnelingDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	Vs := dos.Omegas(-12.0, 12.0, 2401)
+	G, err := DIdV(env, Vs, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0.0
+	for _, g := range G {
+		total += g * (Vs[1] - Vs[0])
+	}
+	if math.Abs(total-1.0) > 1e-2 {
+		t.Fatalf("dI/dV integrates to %v", total)
+	}
+}
+
+// The Dynes broadening should fill in the gap, raising the normalized
+// zero-bias conductance, and far above the gap the normalized conductance
+// should approach 1.
+func TestNormalizedDIdV(t *testing.T) {
+	env, err := tunnelingDefaultEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := 0.0
+	for _, Gamma := range []float64{0.01, 0.05, 0.2} {
+		G, err := NormalizedDIdV(env, []float64{0.0}, Gamma)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if G[0] <= last {
+			t.Fatalf("zero-bias conductance %v at Gamma = %v is not above %v found with smaller Gamma", G[0], Gamma, last)
+		}
+		last = G[0]
+	}
+	if last >= 1.0 {
+		t.Fatalf("zero-bias conductance %v is not suppressed by the gap", last)
+	}
+	far, err := NormalizedDIdV(env, []float64{4.0, 5.0, 6.0}, 0.05)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range far {
+		if math.Abs(g-1.0) > 1e-2 {
+			t.Fatalf("normalized dI/dV %v far outside the gap differs from 1", far)
+		}
+	}
+}
+
+func tunnelingDefaultEnv() (*tempAll.Environment, error) {
+	return tempAll.NewEnvironment(`{"PointsPerSide": 16, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 20.0, "Mu_h": 0.5, "F0": 0.1}`)
+}
@@ -0,0 +1,160 @@
+// Conversion of model quantities into physical units.
+//
+// All energies used internally are dimensionless, measured in the same units
+// as the hopping energy T0, and temperatures are energies with k_B = 1. If an
+// Environment gives the physical value of T0 (PhysicalT0 in PhysicalT0Unit),
+// the functions here convert outputs into K, meV and J/(mol K) without
+// changing any of the dimensionless internals.
+package units
+
+import (
+	"fmt"
+	"math"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+const MEV_PER_K = 8.617333262e-2 // Boltzmann constant (meV/K)
+const GAS_CONSTANT = 8.314462618 // N_A * k_B (J/(mol K))
+const SPECIFIC_HEAT_UNIT = "J/(mol K)"
+const JOULE_PER_MEV = 1.602176634e-22
+const ELEMENTARY_CHARGE = 1.602176634e-19 // C
+const HBAR = 1.054571817e-34              // J s
+const MU_0 = 1.25663706212e-6             // vacuum permeability (N/A^2)
+
+// Return the value in meV of one unit of model energy in env.
+func MeVPerUnit(env *tempAll.Environment) (float64, error) {
+	var T0meV float64
+	switch env.PhysicalT0Unit {
+	case "meV":
+		T0meV = env.PhysicalT0
+	case "K":
+		T0meV = env.PhysicalT0 * MEV_PER_K
+	case "":
+		return 0.0, fmt.Errorf("Environment has no physical energy scale (set PhysicalT0 and PhysicalT0Unit)")
+	default:
+		return 0.0, fmt.Errorf("unknown PhysicalT0Unit %q", env.PhysicalT0Unit)
+	}
+	if env.T0 == 0.0 {
+		return 0.0, fmt.Errorf("cannot set physical energy scale with T0 = 0")
+	}
+	return T0meV / env.T0, nil
+}
+
+// True if env has a physical energy scale.
+func HasPhysicalScale(env *tempAll.Environment) bool {
+	_, err := MeVPerUnit(env)
+	return err == nil
+}
+
+// Convert the model energy E to meV.
+func Energy(env *tempAll.Environment, E float64) (float64, error) {
+	scale, err := MeVPerUnit(env)
+	if err != nil {
+		return 0.0, err
+	}
+	return E * scale, nil
+}
+
+// Convert the model temperature T to K.
+func Temperature(env *tempAll.Environment, T float64) (float64, error) {
+	E, err := Energy(env, T)
+	if err != nil {
+		return 0.0, err
+	}
+	return E / MEV_PER_K, nil
+}
+
+// Temperature of env (1/Beta) in K. For a solved Environment at T_c or T_p,
+// this is T_c or T_p.
+func Temp(env *tempAll.Environment) (float64, error) {
+	return Temperature(env, 1.0/env.Beta)
+}
+
+// Holon chemical potential of env in meV.
+func Mu_h(env *tempAll.Environment) (float64, error) {
+	return Energy(env, env.Mu_h)
+}
+
+// Pair chemical potential of env in meV.
+func Mu_b(env *tempAll.Environment) (float64, error) {
+	return Energy(env, env.Mu_b)
+}
+
+// Maximum over the Brillouin zone of |Delta_h(k)| in meV.
+// Delta_h(k) = 4*(T0 + Tz)*F0*g(k); see Environment.GapFormMax for max |g|.
+func GapAmplitude(env *tempAll.Environment) (float64, error) {
+	gap := 4.0 * (env.T0 + env.Tz) * math.Abs(env.F0) * env.GapFormMax()
+	return Energy(env, gap)
+}
+
+// London penetration depth in nm for a stack of planes separated by
+// layerSpacing (in nm), each with superfluid stiffness rhoS (in units of T0,
+// as returned by tempLow.SuperfluidStiffness):
+// lambda^{-2} = mu_0 e^2 rho_s / (hbar^2 d).
+func PenetrationDepth(env *tempAll.Environment, rhoS, layerSpacing float64) (float64, error) {
+	if rhoS <= 0.0 {
+		return 0.0, fmt.Errorf("penetration depth requires positive stiffness; got %v", rhoS)
+	}
+	if layerSpacing <= 0.0 {
+		return 0.0, fmt.Errorf("layer spacing must be positive; got %v", layerSpacing)
+	}
+	rhoMeV, err := Energy(env, rhoS)
+	if err != nil {
+		return 0.0, err
+	}
+	invLambda2 := MU_0 * ELEMENTARY_CHARGE * ELEMENTARY_CHARGE * rhoMeV * JOULE_PER_MEV / (HBAR * HBAR * layerSpacing * 1e-9)
+	return 1e9 / math.Sqrt(invLambda2), nil
+}
+
+// Convert the specific heat per lattice site, C (in units of k_B), to
+// J/(mol K) per mole of lattice sites. This conversion does not depend on
+// the energy scale.
+func SpecificHeat(C float64) float64 {
+	return C * GAS_CONSTANT
+}
+
+// Temperature of the Environment contained in data in K; for use as
+// XFunc/YFunc in a plots.GraphVars.
+func GetTempK(data interface{}) float64 {
+	env := data.(tempAll.Environment)
+	T, err := Temp(&env)
+	if err != nil {
+		panic(err)
+	}
+	return T
+}
+
+// Holon chemical potential of the Environment contained in data in meV; for
+// use as XFunc/YFunc in a plots.GraphVars.
+func GetMu_hMeV(data interface{}) float64 {
+	env := data.(tempAll.Environment)
+	mu, err := Mu_h(&env)
+	if err != nil {
+		panic(err)
+	}
+	return mu
+}
+
+// Axis label for a temperature quantity with LaTeX symbol sym (such as
+// "T_c"): in K if env has a physical scale, or relative to t_0 otherwise.
+func TempLabel(env *tempAll.Environment, sym string) string {
+	if HasPhysicalScale(env) {
+		return fmt.Sprintf("$%s$ (K)", sym)
+	}
+	return fmt.Sprintf("$%s/t_0$", sym)
+}
+
+// Axis label for an energy quantity with LaTeX symbol sym (such as
+// "\\mu_h"): in meV if env has a physical scale, or relative to t_0
+// otherwise.
+func EnergyLabel(env *tempAll.Environment, sym string) string {
+	if HasPhysicalScale(env) {
+		return fmt.Sprintf("$%s$ (meV)", sym)
+	}
+	return fmt.Sprintf("$%s/t_0$", sym)
+}
+
+// Axis label for a specific heat quantity with LaTeX symbol sym.
+func SpecificHeatLabel(sym string) string {
+	return fmt.Sprintf("$%s$ (%s)", sym, SPECIFIC_HEAT_UNIT)
+}
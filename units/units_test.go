@@ -0,0 +1,72 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+import "github.com/tflovorn/scExplorer/tempAll"
+
+func unitsTestEnv(T0, physicalT0 float64, unit string) (*tempAll.Environment, error) {
+	env, err := tempAll.NewEnvironment(`{"PointsPerSide": 8, "X": 0.1, "T0": 1.0, "Thp": 0.1, "Tz": 0.1, "Alpha": -1, "Beta": 10.0}`)
+	if err != nil {
+		return nil, err
+	}
+	env.T0 = T0
+	env.PhysicalT0 = physicalT0
+	env.PhysicalT0Unit = unit
+	return env, nil
+}
+
+// Temperatures should convert consistently whether T0 is given in meV or K.
+func TestTemperatureConversion(t *testing.T) {
+	envMeV, err := unitsTestEnv(1.0, 100.0, "meV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	envK, err := unitsTestEnv(1.0, 100.0/MEV_PER_K, "K")
+	if err != nil {
+		t.Fatal(err)
+	}
+	TMeV, err := Temp(envMeV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	TK, err := Temp(envK)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := 10.0 / MEV_PER_K // T = 0.1 T0 = 10 meV
+	if math.Abs(TMeV-expected) > 1e-9 || math.Abs(TK-expected) > 1e-9 {
+		t.Fatalf("incorrect temperature conversion: got %v and %v, expected %v", TMeV, TK, expected)
+	}
+}
+
+// The physical scale is given for T0, so model energies scale as 1/T0.
+func TestEnergyScale(t *testing.T) {
+	env, err := unitsTestEnv(2.0, 100.0, "meV")
+	if err != nil {
+		t.Fatal(err)
+	}
+	E, err := Energy(env, 1.0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(E-50.0) > 1e-12 {
+		t.Fatalf("incorrect energy conversion: got %v, expected 50", E)
+	}
+}
+
+// Conversion without a physical scale should fail.
+func TestNoPhysicalScale(t *testing.T) {
+	env, err := unitsTestEnv(1.0, 0.0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Temp(env)
+	if err == nil {
+		t.Fatalf("expected error converting without physical scale")
+	}
+	if TempLabel(env, "T_c") != "$T_c/t_0$" {
+		t.Fatalf("incorrect dimensionless label %s", TempLabel(env, "T_c"))
+	}
+}